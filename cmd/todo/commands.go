@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/services/todo/client"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+func runAdd(a *app, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	description := fs.String("d", "", "description")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: todo add <title> -d <description>")
+	}
+
+	resp, err := a.client.Create(a.ctx, &todo.CreateRequest{
+		Title:       args[0],
+		Description: *description,
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.printTodo(todo.ViewResponse(*resp))
+}
+
+func runList(a *app, args []string) error {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	done := fs.String("done", "", "only todos with this done state")
+	favorite := fs.String("favorite", "", "only todos with this favorite state")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	params := client.GetAllParams{}
+	var err error
+	if params.IsDone, err = parseOptionalBool("done", *done); err != nil {
+		return err
+	}
+	if params.IsFavorite, err = parseOptionalBool("favorite", *favorite); err != nil {
+		return err
+	}
+
+	resp, err := a.client.GetAll(a.ctx, params)
+	if err != nil {
+		return err
+	}
+
+	return a.printTodos(resp)
+}
+
+func runShow(a *app, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.GetByID(a.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return a.printTodo(*resp)
+}
+
+func runEdit(a *app, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	title := fs.String("title", "", "new title")
+	description := fs.String("d", "", "new description")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	current, err := a.client.GetByID(a.ctx, id)
+	if err != nil {
+		return err
+	}
+
+	form := &todo.UpdateRequest{
+		Title:       current.Title,
+		Description: current.Description,
+		IsDone:      strconv.FormatBool(current.IsDone),
+		IsFavorite:  strconv.FormatBool(current.IsFavorite),
+	}
+	if *title != "" {
+		form.Title = *title
+	}
+	if *description != "" {
+		form.Description = *description
+	}
+
+	resp, err := a.client.UpdateData(a.ctx, id, form)
+	if err != nil {
+		return err
+	}
+
+	return a.printTodo(*resp)
+}
+
+func runDone(a *app, args []string) error {
+	fs := flag.NewFlagSet("done", flag.ContinueOnError)
+	undo := fs.Bool("undo", false, "mark as not done")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.MarkAsDone(a.ctx, id, !*undo); err != nil {
+		return err
+	}
+
+	return runShow(a, args)
+}
+
+func runFavorite(a *app, args []string) error {
+	fs := flag.NewFlagSet("fav", flag.ContinueOnError)
+	undo := fs.Bool("undo", false, "remove from favorites")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.MarkAsFavorite(a.ctx, id, !*undo); err != nil {
+		return err
+	}
+
+	return runShow(a, args)
+}
+
+func runRemove(a *app, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.DeleteByID(a.ctx, id); err != nil {
+		return err
+	}
+
+	if a.profile.Output == "json" {
+		return a.printJSON(map[string]bool{"status": true})
+	}
+
+	fmt.Fprintf(a.out, "deleted todo %d\n", id)
+	return nil
+}
+
+func runSearch(a *app, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: todo search <title>")
+	}
+
+	resp, err := a.client.GetByTitle(a.ctx, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	return a.printTodos(resp)
+}
+
+// runMarkdown syncs a Markdown checklist and writes the ids of created
+// todos back to the file. It fails when any item could not be synced, so
+// it can run from a git hook.
+func runMarkdown(a *app, args []string) error {
+	fs := flag.NewFlagSet("md", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would change")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: todo md <file> [--dry-run]")
+	}
+
+	info, err := os.Stat(args[0])
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.SyncMarkdown(a.ctx, &todo.MarkdownSyncRequest{
+		Document: string(content),
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !*dryRun && resp.Document != string(content) {
+		if err := ioutil.WriteFile(args[0], []byte(resp.Document), info.Mode()); err != nil {
+			return err
+		}
+	}
+
+	if a.profile.Output == "json" {
+		if err := a.printJSON(resp); err != nil {
+			return err
+		}
+	} else {
+		prefix := ""
+		if *dryRun {
+			prefix = "would have "
+		}
+
+		fmt.Fprintf(a.out, "%screated %d, %supdated %d, %d unchanged\n", prefix, len(resp.Created), prefix, len(resp.Updated), resp.Unchanged)
+		for _, item := range resp.Deleted {
+			fmt.Fprintf(a.out, "not in %s: %d %s\n", args[0], item.ID, printable(item.Title))
+		}
+		for _, item := range resp.Errors {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", args[0], item.Row, printable(item.Error))
+		}
+	}
+
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("%d items could not be synced", len(resp.Errors))
+	}
+
+	return nil
+}
+
+// runLogin stores a token in the profile. The service has no login
+// endpoint, so the token is taken from --token or read from stdin.
+func runLogin(a *app, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	token := fs.String("token", "", "api token")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *token == "" {
+		fmt.Fprint(os.Stderr, "token: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		*token = strings.TrimSpace(line)
+	}
+
+	if *token == "" {
+		return fmt.Errorf("token should not be empty")
+	}
+
+	a.profile.Token = *token
+	if err := a.config.save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "logged in to %s as profile %s\n", a.profile.URL, a.profileName)
+	return nil
+}
+
+func runLogout(a *app, args []string) error {
+	a.profile.Token = ""
+	if err := a.config.save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "logged out of profile %s\n", a.profileName)
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const bashCompletion = `# bash completion for todo
+_todo() {
+    local cur prev commands
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    commands="%[1]s"
+
+    case "$prev" in
+        -o)
+            COMPREPLY=($(compgen -W "table json" -- "$cur"))
+            return 0
+            ;;
+        completion)
+            COMPREPLY=($(compgen -W "bash zsh" -- "$cur"))
+            return 0
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "--profile --url -o -d --title --done --favorite --undo --token --dry-run" -- "$cur"))
+        return 0
+    fi
+
+    COMPREPLY=($(compgen -W "$commands" -- "$cur"))
+}
+complete -F _todo todo
+`
+
+const zshCompletion = `#compdef todo
+_todo() {
+    local -a commands
+    commands=(%[1]s)
+
+    _arguments \
+        '--profile[config profile]:profile:' \
+        '--url[server url]:url:' \
+        '-o[output format]:format:(table json)' \
+        '1:command:->command' \
+        '*::arg:->args'
+
+    case $state in
+        command)
+            _describe 'command' commands
+            ;;
+        args)
+            case $words[1] in
+                completion) _values 'shell' bash zsh ;;
+                add) _arguments '-d[description]:description:' ;;
+                edit) _arguments '--title[title]:title:' '-d[description]:description:' ;;
+                ls) _arguments '--done[done state]:bool:(true false)' '--favorite[favorite state]:bool:(true false)' ;;
+                done|fav) _arguments '--undo[undo]' ;;
+                login) _arguments '--token[api token]:token:' ;;
+                md) _arguments '--dry-run[only report changes]' '1:file:_files' ;;
+            esac
+            ;;
+    esac
+}
+_todo "$@"
+`
+
+func runCompletion(a *app, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: todo completion bash|zsh")
+	}
+
+	names := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Fprintf(a.out, bashCompletion, strings.Join(names, " "))
+	case "zsh":
+		fmt.Fprintf(a.out, zshCompletion, strings.Join(names, " "))
+	default:
+		return fmt.Errorf("unknown shell %q", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const defaultURL = "http://localhost:9091"
+
+// Profile holds the settings for one todo server.
+type Profile struct {
+	URL    string `json:"url"`
+	Token  string `json:"token,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+type Config struct {
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// configPath is $TODO_CONFIG or todo/config.json in the user config
+// directory.
+func configPath() (string, error) {
+	if path := os.Getenv("TODO_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "todo", "config.json"), nil
+}
+
+func loadConfig() (*Config, error) {
+	config := &Config{Profiles: make(map[string]*Profile)}
+
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, err
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]*Profile)
+	}
+
+	return config, nil
+}
+
+func (c *Config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+// profile returns the named profile, creating it with defaults when it does
+// not exist yet.
+func (c *Config) profile(name string) *Profile {
+	p, ok := c.Profiles[name]
+	if !ok {
+		p = &Profile{}
+		c.Profiles[name] = p
+	}
+
+	if p.URL == "" {
+		p.URL = defaultURL
+	}
+	if p.Output == "" {
+		p.Output = "table"
+	}
+
+	return p
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/ardiantirta/todo-crud/services/todo/client"
+)
+
+const usage = `usage: todo [--profile name] [--url url] [-o table|json] <command> [args]
+
+commands:
+  add <title> -d <description>     create a todo
+  ls [--done=bool] [--favorite=bool]
+                                   list todos
+  show <id>                        show one todo
+  edit <id> [--title t] [-d d]     change the title or description
+  done <id> [--undo]               mark a todo as done
+  fav <id> [--undo]                mark a todo as favorite
+  rm <id>                          delete a todo
+  search <title>                   search todos by title
+  md <file> [--dry-run]            sync a Markdown checklist and write the
+                                   ids of new todos back to the file
+  tui                              browse and edit todos in a full-screen UI
+  login [--token t]                store a token in the profile
+  logout                           remove the token from the profile
+  completion bash|zsh              print a shell completion script
+`
+
+type command struct {
+	name string
+	run  func(app *app, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"add", runAdd},
+		{"ls", runList},
+		{"show", runShow},
+		{"edit", runEdit},
+		{"done", runDone},
+		{"fav", runFavorite},
+		{"rm", runRemove},
+		{"search", runSearch},
+		{"md", runMarkdown},
+		{"tui", runTUI},
+		{"login", runLogin},
+		{"logout", runLogout},
+		{"completion", runCompletion},
+	}
+}
+
+// app carries what every command needs: the resolved profile, an API
+// client and where to write output.
+type app struct {
+	ctx         context.Context
+	config      *Config
+	profileName string
+	profile     *Profile
+	client      *client.Client
+	out         io.Writer
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "todo:", printable(err.Error()))
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("todo", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	profileName := fs.String("profile", envOr("TODO_PROFILE", "default"), "config profile")
+	url := fs.String("url", "", "server url, overrides the profile")
+	output := fs.String("o", "", "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := config.profile(*profileName)
+	if *url != "" {
+		profile.URL = *url
+	}
+	if *output != "" {
+		profile.Output = *output
+	}
+	if profile.Output != "table" && profile.Output != "json" {
+		return fmt.Errorf("unknown output format %q", profile.Output)
+	}
+
+	c := client.NewClient(profile.URL)
+	if profile.Token != "" {
+		c.Auth = client.BearerToken(profile.Token)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	a := &app{
+		ctx:         ctx,
+		config:      config,
+		profileName: *profileName,
+		profile:     profile,
+		client:      c,
+		out:         os.Stdout,
+	}
+
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(a, fs.Args()[1:])
+		}
+	}
+
+	fs.Usage()
+	return fmt.Errorf("unknown command %q", name)
+}
+
+// parse parses flags that may appear before, between or after positional
+// arguments and returns the positional ones.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func parseID(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("expected exactly one todo id")
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("id should be a positive number")
+	}
+
+	return id, nil
+}
+
+func parseOptionalBool(name, value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("--%s must be true or false", name)
+	}
+
+	return &b, nil
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+
+	return fallback
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+func (c *app) printTodo(data todo.ViewResponse) error {
+	return c.printTodos([]todo.ViewResponse{data})
+}
+
+func (c *app) printTodos(data []todo.ViewResponse) error {
+	if c.profile.Output == "json" {
+		return c.printJSON(data)
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDONE\tFAV\tTITLE\tDESCRIPTION")
+	for _, t := range data {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", t.ID, mark(t.IsDone), mark(t.IsFavorite), printable(t.Title), truncate(printable(t.Description), 50))
+	}
+
+	return w.Flush()
+}
+
+func (c *app) printJSON(data interface{}) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.out, string(content))
+	return err
+}
+
+func mark(b bool) string {
+	if b {
+		return "x"
+	}
+
+	return "-"
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-1]) + "…"
+}
+
+// printable strips the control characters, ESC included, that would let a
+// todo written by someone else move the cursor or recolor the terminal.
+// Tabs and line breaks become spaces so a todo stays on its own row.
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return ' '
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r <= 0x9f):
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestPrintable(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"buy milk", "buy milk"},
+		{"\x1b[2Jcleared", "[2Jcleared"},
+		{"\x1b]0;owned\x07title", "]0;ownedtitle"},
+		{"c1\u009b31mred", "c131mred"},
+		{"two\nlines\tand\rtab", "two lines and tab"},
+		{"del\x7f", "del"},
+		{"kopi ☕", "kopi ☕"},
+	}
+
+	for _, tt := range tests {
+		if got := printable(tt.in); got != tt.want {
+			t.Errorf("printable(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
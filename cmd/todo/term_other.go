@@ -0,0 +1,25 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+var resizeSignals = []os.Signal{}
+
+type terminal struct{}
+
+func openTerminal(fd int) (*terminal, error) {
+	return nil, errors.New("the terminal UI is only supported on linux and darwin")
+}
+
+func (c *terminal) restore() error {
+	return nil
+}
+
+func (c *terminal) size() (int, int) {
+	return 80, 24
+}
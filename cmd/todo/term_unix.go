@@ -0,0 +1,53 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var resizeSignals = []os.Signal{syscall.SIGWINCH}
+
+// terminal puts a tty into raw mode and restores it afterwards.
+type terminal struct {
+	fd    int
+	state unix.Termios
+}
+
+func openTerminal(fd int) (*terminal, error) {
+	state, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *state
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return &terminal{fd: fd, state: *state}, nil
+}
+
+func (c *terminal) restore() error {
+	return unix.IoctlSetTermios(c.fd, ioctlSetTermios, &c.state)
+}
+
+func (c *terminal) size() (int, int) {
+	ws, err := unix.IoctlGetWinsize(c.fd, unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+
+	return int(ws.Col), int(ws.Row)
+}
@@ -0,0 +1,553 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ardiantirta/todo-crud/services/todo/client"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	modeList = iota
+	modeSearch
+	modeEditTitle
+	modeEditDescription
+	modeNewTitle
+	modeNewDescription
+)
+
+const help = "j/k move  space done  f fav  1 done-filter  2 fav-filter  / search  e title  E desc  n new  r refresh  q quit"
+
+type key struct {
+	r    rune
+	name string
+}
+
+type loaded struct {
+	seq   int
+	todos []todo.ViewResponse
+	err   error
+}
+
+// tui is a full-screen todo browser. All state is owned by the loop in
+// run; network calls happen in goroutines that report back over channels.
+type tui struct {
+	a    *app
+	term *terminal
+	out  *bufio.Writer
+
+	todos  []todo.ViewResponse
+	cursor int
+	offset int
+
+	doneFilter     string
+	favoriteFilter string
+	search         string
+
+	mode     int
+	input    []rune
+	newTitle string
+	status   string
+
+	seq     int
+	results chan loaded
+	actions chan error
+	reload  chan struct{}
+}
+
+func runTUI(a *app, args []string) error {
+	term, err := openTerminal(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.restore()
+
+	t := &tui{
+		a:       a,
+		term:    term,
+		out:     bufio.NewWriter(os.Stdout),
+		results: make(chan loaded, 1),
+		actions: make(chan error, 1),
+		reload:  make(chan struct{}, 1),
+	}
+
+	fmt.Fprint(t.out, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(t.out, "\x1b[?25h\x1b[?1049l")
+		t.out.Flush()
+	}()
+
+	return t.run()
+}
+
+func (c *tui) run() error {
+	keys := make(chan key, 16)
+	go readKeys(keys)
+	go c.watch()
+
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, resizeSignals...)
+	defer signal.Stop(resize)
+
+	var debounce *time.Timer
+	c.load()
+	c.render()
+
+	for {
+		select {
+		case <-c.a.ctx.Done():
+			return nil
+		case <-resize:
+		case <-c.reload:
+			c.load()
+		case r := <-c.results:
+			if r.seq != c.seq {
+				continue
+			}
+			if r.err != nil {
+				c.status = r.err.Error()
+			} else {
+				c.todos = r.todos
+				c.clamp()
+			}
+		case err := <-c.actions:
+			if err != nil {
+				c.status = err.Error()
+			}
+			c.load()
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+
+			searchBefore := c.search
+			if quit := c.handle(k); quit {
+				return nil
+			}
+
+			if c.search != searchBefore {
+				if debounce != nil {
+					debounce.Stop()
+				}
+				debounce = time.AfterFunc(150*time.Millisecond, c.requestReload)
+			}
+		}
+
+		c.render()
+	}
+}
+
+func (c *tui) handle(k key) bool {
+	if k.name == "ctrl-c" {
+		return true
+	}
+
+	if c.mode != modeList {
+		c.handleInput(k)
+		return false
+	}
+
+	c.status = ""
+	switch {
+	case k.r == 'q':
+		return true
+	case k.r == 'j' || k.name == "down":
+		c.cursor++
+	case k.r == 'k' || k.name == "up":
+		c.cursor--
+	case k.name == "pgdown":
+		c.cursor += c.pageSize()
+	case k.name == "pgup":
+		c.cursor -= c.pageSize()
+	case k.r == 'g':
+		c.cursor = 0
+	case k.r == 'G':
+		c.cursor = len(c.todos) - 1
+	case k.r == ' ':
+		if t := c.selected(); t != nil {
+			id, isDone := int(t.ID), !t.IsDone
+			c.do(func() error { return c.a.client.MarkAsDone(c.a.ctx, id, isDone) })
+		}
+	case k.r == 'f':
+		if t := c.selected(); t != nil {
+			id, isFavorite := int(t.ID), !t.IsFavorite
+			c.do(func() error { return c.a.client.MarkAsFavorite(c.a.ctx, id, isFavorite) })
+		}
+	case k.r == '1':
+		c.doneFilter = nextFilter(c.doneFilter)
+		c.load()
+	case k.r == '2':
+		c.favoriteFilter = nextFilter(c.favoriteFilter)
+		c.load()
+	case k.r == '/':
+		c.mode = modeSearch
+		c.input = []rune(c.search)
+	case k.r == 'e':
+		if t := c.selected(); t != nil {
+			c.mode = modeEditTitle
+			c.input = []rune(t.Title)
+		}
+	case k.r == 'E':
+		if t := c.selected(); t != nil {
+			c.mode = modeEditDescription
+			c.input = []rune(t.Description)
+		}
+	case k.r == 'n':
+		c.mode = modeNewTitle
+		c.input = nil
+	case k.r == 'r':
+		c.load()
+	}
+
+	c.clamp()
+	return false
+}
+
+// handleInput edits the prompt line. Search updates as the user types;
+// the other prompts are submitted with enter and dropped with escape.
+func (c *tui) handleInput(k key) {
+	switch k.name {
+	case "esc":
+		if c.mode == modeSearch {
+			c.search = ""
+		}
+		c.mode = modeList
+		c.input = nil
+		return
+	case "backspace":
+		if len(c.input) > 0 {
+			c.input = c.input[:len(c.input)-1]
+		}
+	case "enter":
+		c.submit()
+		return
+	case "":
+		if k.r >= ' ' {
+			c.input = append(c.input, k.r)
+		}
+	}
+
+	if c.mode == modeSearch {
+		c.search = string(c.input)
+	}
+}
+
+func (c *tui) submit() {
+	value := string(c.input)
+	mode := c.mode
+	c.mode = modeList
+	c.input = nil
+
+	switch mode {
+	case modeEditTitle, modeEditDescription:
+		t := c.selected()
+		if t == nil {
+			return
+		}
+
+		form := &todo.UpdateRequest{
+			Title:       t.Title,
+			Description: t.Description,
+			IsDone:      strconv.FormatBool(t.IsDone),
+			IsFavorite:  strconv.FormatBool(t.IsFavorite),
+		}
+		if mode == modeEditTitle {
+			form.Title = value
+		} else {
+			form.Description = value
+		}
+
+		id := int(t.ID)
+		c.do(func() error {
+			_, err := c.a.client.UpdateData(c.a.ctx, id, form)
+			return err
+		})
+	case modeNewTitle:
+		c.newTitle = value
+		c.mode = modeNewDescription
+	case modeNewDescription:
+		form := &todo.CreateRequest{Title: c.newTitle, Description: value}
+		c.do(func() error {
+			_, err := c.a.client.Create(c.a.ctx, form)
+			return err
+		})
+	}
+}
+
+func (c *tui) do(fn func() error) {
+	c.status = "saving..."
+	go func() {
+		c.actions <- fn()
+	}()
+}
+
+func (c *tui) load() {
+	c.seq++
+	seq := c.seq
+	search := c.search
+	params := client.GetAllParams{}
+	params.IsDone, _ = parseOptionalBool("done", c.doneFilter)
+	params.IsFavorite, _ = parseOptionalBool("favorite", c.favoriteFilter)
+
+	go func() {
+		var todos []todo.ViewResponse
+		var err error
+		if search != "" {
+			todos, err = c.a.client.GetByTitle(c.a.ctx, search)
+			todos = filter(todos, params)
+		} else {
+			todos, err = c.a.client.GetAll(c.a.ctx, params)
+		}
+
+		select {
+		case <-c.results:
+		default:
+		}
+		c.results <- loaded{seq: seq, todos: todos, err: err}
+	}()
+}
+
+func (c *tui) requestReload() {
+	select {
+	case c.reload <- struct{}{}:
+	default:
+	}
+}
+
+// watch asks for a reload on every change event from the websocket
+// channel, and polls instead while the channel is unavailable.
+func (c *tui) watch() {
+	target := "ws" + strings.TrimPrefix(c.a.client.BaseURL, "http") + "/ws?name=" + url.QueryEscape(os.Getenv("USER"))
+	header := http.Header{}
+	if c.a.profile.Token != "" {
+		header.Set("Authorization", "Bearer "+c.a.profile.Token)
+	}
+
+	for c.a.ctx.Err() == nil {
+		conn, _, err := websocket.DefaultDialer.Dial(target, header)
+		if err == nil {
+			err = conn.WriteJSON(map[string]string{"type": "subscribe", "topic": "todos"})
+			for err == nil {
+				msg := struct {
+					Type string `json:"type"`
+				}{}
+				if err = conn.ReadJSON(&msg); err == nil && msg.Type == "event" {
+					c.requestReload()
+				}
+			}
+			conn.Close()
+		}
+
+		select {
+		case <-c.a.ctx.Done():
+		case <-time.After(5 * time.Second):
+			c.requestReload()
+		}
+	}
+}
+
+func (c *tui) render() {
+	width, _ := c.term.size()
+	w := c.out
+
+	fmt.Fprint(w, "\x1b[H\x1b[2J")
+
+	header := fmt.Sprintf(" todo  %s  done:%s  fav:%s  search:%q  %d items",
+		c.a.profile.URL, filterName(c.doneFilter), filterName(c.favoriteFilter), c.search, len(c.todos))
+	fmt.Fprintf(w, "\x1b[7m%s\x1b[0m\r\n", pad(header, width))
+
+	page := c.pageSize()
+	for i := 0; i < page; i++ {
+		n := c.offset + i
+		if n >= len(c.todos) {
+			fmt.Fprint(w, "\r\n")
+			continue
+		}
+
+		t := c.todos[n]
+		done := "[ ]"
+		if t.IsDone {
+			done = "[x]"
+		}
+		favorite := " "
+		if t.IsFavorite {
+			favorite = "*"
+		}
+
+		line := pad(fmt.Sprintf(" %s %s %4d  %s", done, favorite, t.ID, printable(t.Title)), width)
+		if n == c.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		fmt.Fprint(w, line, "\r\n")
+	}
+
+	fmt.Fprint(w, strings.Repeat("─", width), "\r\n")
+	if t := c.selected(); t != nil {
+		fmt.Fprint(w, pad(" "+printable(t.Description), width), "\r\n")
+	} else {
+		fmt.Fprint(w, "\r\n")
+	}
+
+	switch c.mode {
+	case modeSearch:
+		fmt.Fprint(w, pad("search: "+printable(string(c.input))+"_", width))
+	case modeEditTitle:
+		fmt.Fprint(w, pad("title: "+printable(string(c.input))+"_", width))
+	case modeEditDescription:
+		fmt.Fprint(w, pad("description: "+printable(string(c.input))+"_", width))
+	case modeNewTitle:
+		fmt.Fprint(w, pad("new title: "+printable(string(c.input))+"_", width))
+	case modeNewDescription:
+		fmt.Fprint(w, pad("new description: "+printable(string(c.input))+"_", width))
+	default:
+		if c.status != "" {
+			fmt.Fprint(w, pad(printable(c.status), width))
+		} else {
+			fmt.Fprint(w, pad(help, width))
+		}
+	}
+
+	w.Flush()
+}
+
+func (c *tui) pageSize() int {
+	_, height := c.term.size()
+	if height < 6 {
+		return 1
+	}
+
+	return height - 4
+}
+
+func (c *tui) clamp() {
+	if c.cursor >= len(c.todos) {
+		c.cursor = len(c.todos) - 1
+	}
+	if c.cursor < 0 {
+		c.cursor = 0
+	}
+
+	page := c.pageSize()
+	if c.cursor < c.offset {
+		c.offset = c.cursor
+	}
+	if c.cursor >= c.offset+page {
+		c.offset = c.cursor - page + 1
+	}
+}
+
+func (c *tui) selected() *todo.ViewResponse {
+	if c.cursor < 0 || c.cursor >= len(c.todos) {
+		return nil
+	}
+
+	return &c.todos[c.cursor]
+}
+
+func readKeys(keys chan<- key) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+
+		b := buf[:n]
+		if b[0] == 0x1b {
+			keys <- escapeKey(b)
+			continue
+		}
+
+		for len(b) > 0 {
+			switch b[0] {
+			case 0x03:
+				keys <- key{name: "ctrl-c"}
+				b = b[1:]
+				continue
+			case '\r', '\n':
+				keys <- key{name: "enter"}
+				b = b[1:]
+				continue
+			case 0x7f, 0x08:
+				keys <- key{name: "backspace"}
+				b = b[1:]
+				continue
+			}
+
+			r, size := utf8.DecodeRune(b)
+			keys <- key{r: r}
+			b = b[size:]
+		}
+	}
+}
+
+func escapeKey(b []byte) key {
+	switch string(b) {
+	case "\x1b":
+		return key{name: "esc"}
+	case "\x1b[A", "\x1bOA":
+		return key{name: "up"}
+	case "\x1b[B", "\x1bOB":
+		return key{name: "down"}
+	case "\x1b[5~":
+		return key{name: "pgup"}
+	case "\x1b[6~":
+		return key{name: "pgdown"}
+	}
+
+	return key{name: "unknown"}
+}
+
+func filter(todos []todo.ViewResponse, params client.GetAllParams) []todo.ViewResponse {
+	result := make([]todo.ViewResponse, 0, len(todos))
+	for _, t := range todos {
+		if params.IsDone != nil && t.IsDone != *params.IsDone {
+			continue
+		}
+		if params.IsFavorite != nil && t.IsFavorite != *params.IsFavorite {
+			continue
+		}
+		result = append(result, t)
+	}
+
+	return result
+}
+
+func nextFilter(value string) string {
+	switch value {
+	case "":
+		return "false"
+	case "false":
+		return "true"
+	}
+
+	return ""
+}
+
+func filterName(value string) string {
+	if value == "" {
+		return "any"
+	}
+
+	return value
+}
+
+func pad(s string, width int) string {
+	r := []rune(s)
+	if len(r) > width {
+		return string(r[:width])
+	}
+
+	return s + strings.Repeat(" ", width-len(r))
+}
@@ -0,0 +1,294 @@
+// Package cors answers cross-origin requests by a policy that can differ
+// per path prefix and change while the server runs.
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// AnyOrigin allows every origin. It cannot be combined with credentials
+// or the Authorization header.
+const AnyOrigin = "*"
+
+// Policy decides which cross-origin requests are allowed. No allowed
+// origins means no cross-origin access at all.
+type Policy struct {
+	// AllowedOrigins are origins such as https://app.example.com. The
+	// host may start with a *. label, as in https://*.example.com, to
+	// allow every subdomain, but not the domain itself.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight answer. 0 leaves
+	// it to the browser.
+	MaxAge time.Duration
+}
+
+// Validate checks the origins and rejects a policy that lets any site
+// send credentials.
+func (p Policy) Validate() error {
+	for _, origin := range p.AllowedOrigins {
+		if origin == AnyOrigin {
+			if p.AllowCredentials {
+				return fmt.Errorf("origin %q cannot allow credentials", AnyOrigin)
+			}
+			for _, header := range p.AllowedHeaders {
+				if http.CanonicalHeaderKey(header) == "Authorization" {
+					return fmt.Errorf("origin %q cannot allow the Authorization header", AnyOrigin)
+				}
+			}
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return fmt.Errorf("origin %q should be scheme://host[:port]", origin)
+		}
+		if strings.Contains(strings.TrimPrefix(u.Host, "*."), "*") {
+			return fmt.Errorf("origin %q may only start its host with *.", origin)
+		}
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("max age must not be negative, got %s", p.MaxAge)
+	}
+
+	return nil
+}
+
+// CORS is the middleware. Routes maps a path prefix to the policy of the
+// paths under it, matched by whole segments so /todo does not cover
+// /todos; the longest matching prefix wins, and other paths get the
+// default policy.
+type CORS struct {
+	rules atomic.Value
+}
+
+type rules struct {
+	fallback *policy
+	routes   []route
+}
+
+type route struct {
+	prefix string
+	policy *policy
+}
+
+type policy struct {
+	any         bool
+	origins     map[string]bool
+	subdomains  []string
+	methods     map[string]bool
+	headers     map[string]bool
+	credentials bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+func New(fallback Policy, routes map[string]Policy) (*CORS, error) {
+	c := &CORS{}
+	if err := c.Update(fallback, routes); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Update replaces every policy at once. Requests already answered by the
+// old policies are not affected.
+func (c *CORS) Update(fallback Policy, routes map[string]Policy) error {
+	compiled, err := compile(fallback)
+	if err != nil {
+		return err
+	}
+	next := &rules{fallback: compiled}
+	for prefix, p := range routes {
+		compiled, err := compile(p)
+		if err != nil {
+			return fmt.Errorf("route %s: %s", prefix, err)
+		}
+		next.routes = append(next.routes, route{prefix: prefix, policy: compiled})
+	}
+	sort.Slice(next.routes, func(i, j int) bool { return len(next.routes[i].prefix) > len(next.routes[j].prefix) })
+
+	c.rules.Store(next)
+	return nil
+}
+
+// Middleware answers preflight requests and adds the CORS headers to the
+// responses of allowed origins. An OPTIONS request without
+// Access-Control-Request-Method is not a preflight, so it reaches next;
+// CalDAV clients send them to discover the server.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p := c.rules.Load().(*rules).match(r.URL.Path)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			p.preflight(w, r, origin)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if p.allowsOrigin(origin) {
+			p.allow(w, origin)
+			if p.exposeHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", p.exposeHeaders)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CheckOrigin reports whether r may be served given its Origin, for
+// requests browsers send cross-site without a preflight, such as
+// WebSocket upgrades. Requests without an Origin do not come from a
+// browser page and same-origin requests are allowed; any other origin
+// must be allowed by the policy of the path.
+func (c *CORS) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	return c.rules.Load().(*rules).match(r.URL.Path).allowsOrigin(origin)
+}
+
+func (c *rules) match(path string) *policy {
+	for _, route := range c.routes {
+		dir := strings.TrimSuffix(route.prefix, "/")
+		if path == route.prefix || path == dir || strings.HasPrefix(path, dir+"/") {
+			return route.policy
+		}
+	}
+
+	return c.fallback
+}
+
+func (c *policy) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	if !c.allowsOrigin(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if !c.methods[strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))] {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !c.headers[http.CanonicalHeaderKey(header)] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	c.allow(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", c.allowMethods)
+	if c.allowHeaders != "" {
+		w.Header().Set("Access-Control-Allow-Headers", c.allowHeaders)
+	}
+	if c.maxAge != "" {
+		w.Header().Set("Access-Control-Max-Age", c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *policy) allow(w http.ResponseWriter, origin string) {
+	if c.any {
+		w.Header().Set("Access-Control-Allow-Origin", AnyOrigin)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if c.credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (c *policy) allowsOrigin(origin string) bool {
+	if c.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if c.origins[origin] {
+		return true
+	}
+	for _, pattern := range c.subdomains {
+		scheme := pattern[:strings.Index(pattern, "*")]
+		suffix := pattern[len(scheme)+1:]
+		if strings.HasPrefix(origin, scheme) && strings.HasSuffix(origin, suffix) && len(origin) > len(scheme)+len(suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compile(p Policy) (*policy, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	compiled := &policy{
+		origins:     make(map[string]bool),
+		methods:     make(map[string]bool),
+		headers:     make(map[string]bool),
+		credentials: p.AllowCredentials,
+	}
+	for _, origin := range p.AllowedOrigins {
+		switch {
+		case origin == AnyOrigin:
+			compiled.any = true
+		case strings.Contains(origin, "://*."):
+			compiled.subdomains = append(compiled.subdomains, strings.ToLower(origin))
+		default:
+			compiled.origins[strings.ToLower(origin)] = true
+		}
+	}
+
+	var methods, headers, exposed []string
+	for _, method := range p.AllowedMethods {
+		method = strings.ToUpper(method)
+		compiled.methods[method] = true
+		methods = append(methods, method)
+	}
+	for _, header := range p.AllowedHeaders {
+		header = http.CanonicalHeaderKey(header)
+		compiled.headers[header] = true
+		headers = append(headers, header)
+	}
+	for _, header := range p.ExposedHeaders {
+		exposed = append(exposed, http.CanonicalHeaderKey(header))
+	}
+	compiled.allowMethods = strings.Join(methods, ", ")
+	compiled.allowHeaders = strings.Join(headers, ", ")
+	compiled.exposeHeaders = strings.Join(exposed, ", ")
+	if p.MaxAge > 0 {
+		compiled.maxAge = strconv.Itoa(int(p.MaxAge / time.Second))
+	}
+
+	return compiled, nil
+}
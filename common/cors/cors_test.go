@@ -0,0 +1,239 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func serve(c *CORS, method, path, origin string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+
+	w := httptest.NewRecorder()
+	c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})).ServeHTTP(w, r)
+	return w
+}
+
+func TestSubdomainOrigins(t *testing.T) {
+	c, err := New(Policy{AllowedOrigins: []string{"https://*.example.com", "http://localhost:3000"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		origin string
+		allow  bool
+	}{
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://APP.Example.com", true},
+		{"https://example.com", false},
+		{"https://.example.com", false},
+		{"http://app.example.com", false},
+		{"https://evilexample.com", false},
+		{"https://app.example.com.evil.net", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+	}
+	for _, test := range tests {
+		w := serve(c, http.MethodGet, "/todo", test.origin, nil)
+		got := w.Header().Get("Access-Control-Allow-Origin")
+		if allowed := got == test.origin; allowed != test.allow {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q", test.origin, got)
+		}
+		if w.Code != http.StatusTeapot {
+			t.Errorf("%s: a simple request was not passed on, status %d", test.origin, w.Code)
+		}
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	c, err := New(Policy{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"Content-Type"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		status  int
+	}{
+		{"allowed", "https://app.example.com", "PUT", "content-type", http.StatusNoContent},
+		{"origin not allowed", "https://evil.example.com", "PUT", "", http.StatusForbidden},
+		{"method not allowed", "https://app.example.com", "DELETE", "", http.StatusMethodNotAllowed},
+		{"header not allowed", "https://app.example.com", "PUT", "Content-Type, X-Secret", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		header := http.Header{"Access-Control-Request-Method": {test.method}}
+		if test.headers != "" {
+			header.Set("Access-Control-Request-Headers", test.headers)
+		}
+		w := serve(c, http.MethodOptions, "/todo/1", test.origin, header)
+		if w.Code != test.status {
+			t.Errorf("%s: status = %d, want %d", test.name, w.Code, test.status)
+		}
+		if w.Code == http.StatusNoContent && w.Header().Get("Access-Control-Allow-Methods") != "GET, PUT" {
+			t.Errorf("%s: Access-Control-Allow-Methods = %q", test.name, w.Header().Get("Access-Control-Allow-Methods"))
+		}
+	}
+
+	// Without Access-Control-Request-Method an OPTIONS request is not a
+	// preflight and reaches the handler, as CalDAV discovery needs.
+	if w := serve(c, http.MethodOptions, "/dav/", "https://app.example.com", nil); w.Code != http.StatusTeapot {
+		t.Errorf("plain OPTIONS: status = %d", w.Code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		policy  Policy
+		message string
+	}{
+		{Policy{AllowedOrigins: []string{AnyOrigin}, AllowCredentials: true}, "credentials"},
+		{Policy{AllowedOrigins: []string{AnyOrigin}, AllowedHeaders: []string{"authorization"}}, "Authorization"},
+		{Policy{AllowedOrigins: []string{"app.example.com"}}, "scheme://host"},
+		{Policy{AllowedOrigins: []string{"https://app.example.com/path"}}, "scheme://host"},
+		{Policy{AllowedOrigins: []string{"https://app.*.com"}}, "only start"},
+		{Policy{MaxAge: -1}, "negative"},
+	}
+	for _, test := range tests {
+		err := test.policy.Validate()
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%+v: err = %v, want it to mention %q", test.policy, err, test.message)
+		}
+	}
+
+	if err := (Policy{AllowedOrigins: []string{AnyOrigin}, AllowedHeaders: []string{"Content-Type"}}).Validate(); err != nil {
+		t.Errorf("any origin without credentials: %s", err)
+	}
+	if _, err := New(Policy{}, map[string]Policy{"/dav": {AllowedOrigins: []string{AnyOrigin}, AllowCredentials: true}}); err == nil || !strings.Contains(err.Error(), "/dav") {
+		t.Errorf("invalid route policy: err = %v", err)
+	}
+}
+
+func TestLongestPrefixWins(t *testing.T) {
+	c, err := New(Policy{AllowedOrigins: []string{"https://default.example"}}, map[string]Policy{
+		"/todo":        {AllowedOrigins: []string{"https://todo.example"}},
+		"/todo/export": {AllowedOrigins: []string{"https://export.example"}},
+		"/dav/":        {AllowedOrigins: []string{"https://dav.example"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   string
+		origin string
+	}{
+		{"/todo", "https://todo.example"},
+		{"/todo/1", "https://todo.example"},
+		{"/todo/export", "https://export.example"},
+		{"/todo/export/more", "https://export.example"},
+		{"/todo/exports", "https://todo.example"},
+		{"/todos", "https://default.example"},
+		{"/todoexport", "https://default.example"},
+		{"/dav", "https://dav.example"},
+		{"/dav/calendars/", "https://dav.example"},
+		{"/", "https://default.example"},
+	}
+	for _, test := range tests {
+		for _, origin := range []string{"https://default.example", "https://todo.example", "https://export.example", "https://dav.example"} {
+			allowed := serve(c, http.MethodGet, test.path, origin, nil).Header().Get("Access-Control-Allow-Origin") != ""
+			if allowed != (origin == test.origin) {
+				t.Errorf("%s from %s: allowed = %v, want only %s", test.path, origin, allowed, test.origin)
+			}
+		}
+	}
+}
+
+func TestUpdateWhileServing(t *testing.T) {
+	c, err := New(Policy{AllowedOrigins: []string{"https://old.example"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Each request sees one policy or the other, never a mix.
+				w := serve(c, http.MethodGet, "/todo", "https://old.example", nil)
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" && got != "https://old.example" {
+					t.Errorf("Access-Control-Allow-Origin = %q", got)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		origin := "https://old.example"
+		if i%2 == 1 {
+			origin = "https://new.example"
+		}
+		if err := c.Update(Policy{AllowedOrigins: []string{origin}}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Update(Policy{AllowedOrigins: []string{AnyOrigin}, AllowCredentials: true}, nil); err == nil {
+		t.Error("an invalid update was accepted")
+	}
+	close(stop)
+	wg.Wait()
+
+	// The last valid update is in effect; the invalid one changed nothing.
+	if got := serve(c, http.MethodGet, "/todo", "https://new.example", nil).Header().Get("Access-Control-Allow-Origin"); got != "https://new.example" {
+		t.Errorf("after updating: Access-Control-Allow-Origin = %q", got)
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	c, err := New(Policy{}, map[string]Policy{"/ws": {AllowedOrigins: []string{"https://app.example.com"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   string
+		origin string
+		allow  bool
+	}{
+		{"/ws", "", true},
+		{"/ws", "http://example.com", true},
+		{"/ws", "https://app.example.com", true},
+		{"/ws", "https://evil.example.com", false},
+		{"/other", "https://app.example.com", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		if got := c.CheckOrigin(r); got != test.allow {
+			t.Errorf("%s from %q: CheckOrigin = %v", test.path, test.origin, got)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package health reports whether the service is alive and whether it is
+// ready for traffic, checking each dependency it needs.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+// Checker checks one dependency. detail, such as a version or a count,
+// is shown in the report whether the check passed or not.
+type Checker interface {
+	Check(ctx context.Context) (detail string, err error)
+}
+
+// CheckerFunc lets a plain function be a Checker.
+type CheckerFunc func(ctx context.Context) (string, error)
+
+func (f CheckerFunc) Check(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// DB checks that the database answers a ping.
+func DB(db *sql.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) (string, error) {
+		return "", db.PingContext(ctx)
+	})
+}
+
+type Report struct {
+	Status     string                     `json:"status"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentReport `json:"components,omitempty"`
+}
+
+type ComponentReport struct {
+	Status    string  `json:"status"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+type component struct {
+	name    string
+	checker Checker
+}
+
+// Health runs the checkers for readiness. Each check gets timeout to
+// answer, and a report is reused for ttl so frequent probes from several
+// load balancers do not hammer the database.
+type Health struct {
+	timeout    time.Duration
+	ttl        time.Duration
+	components []component
+	draining   int32
+
+	mu     sync.Mutex
+	cached *Report
+}
+
+func New(timeout, ttl time.Duration) *Health {
+	return &Health{
+		timeout: timeout,
+		ttl:     ttl,
+	}
+}
+
+// SetTimeouts changes the check timeout and the report ttl while the
+// server runs. The cached report is dropped.
+func (c *Health) SetTimeouts(timeout, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timeout = timeout
+	c.ttl = ttl
+	c.cached = nil
+}
+
+// Add registers a checker under name. Call it before serving.
+func (c *Health) Add(name string, checker Checker) {
+	c.components = append(c.components, component{name: name, checker: checker})
+}
+
+// Drain makes readiness fail from now on, so load balancers stop sending
+// requests while the server shuts down. Liveness is not affected.
+func (c *Health) Drain() {
+	atomic.StoreInt32(&c.draining, 1)
+}
+
+func (c *Health) Draining() bool {
+	return atomic.LoadInt32(&c.draining) == 1
+}
+
+// Check returns the readiness report, running the checkers concurrently
+// unless the last report is younger than ttl.
+func (c *Health) Check(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && time.Since(c.cached.CheckedAt) < c.ttl {
+		return c.cached
+	}
+
+	report := &Report{
+		Status:     StatusOK,
+		Components: make(map[string]ComponentReport, len(c.components)),
+	}
+
+	results := make([]ComponentReport, len(c.components))
+	var wg sync.WaitGroup
+	for i, comp := range c.components {
+		wg.Add(1)
+		go func(i int, comp component) {
+			defer wg.Done()
+			results[i] = c.run(ctx, comp.checker)
+		}(i, comp)
+	}
+	wg.Wait()
+	report.CheckedAt = time.Now()
+
+	for i, comp := range c.components {
+		report.Components[comp.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	c.cached = report
+	return report
+}
+
+func (c *Health) run(ctx context.Context, checker Checker) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := checker.Check(ctx)
+	result := ComponentReport{
+		Status:    StatusOK,
+		Detail:    detail,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Live answers the liveness probe. It only shows the process still
+// serves requests: a dependency being down is no reason to restart it.
+func (c *Health) Live(w http.ResponseWriter, r *http.Request) {
+	write(w, http.StatusOK, &Report{Status: StatusOK, CheckedAt: time.Now()})
+}
+
+// Ready answers the readiness probe with the report of every component,
+// and 503 when one of them fails or the server is draining.
+func (c *Health) Ready(w http.ResponseWriter, r *http.Request) {
+	if c.Draining() {
+		write(w, http.StatusServiceUnavailable, &Report{Status: StatusDraining, CheckedAt: time.Now()})
+		return
+	}
+
+	// The report is shared with other probes, so one probe hanging up
+	// must not cancel the checks.
+	report := c.Check(context.Background())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	write(w, status, report)
+}
+
+func write(w http.ResponseWriter, status int, report *Report) {
+	w.Header().Set("Content-Type", response.JSONContentType+"; charset="+response.JSONCharset)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,49 @@
+package request
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/message"
+)
+
+// LimitBody caps request bodies at limit bytes. A request declaring a
+// longer body is refused with 413; reading past the limit of one that
+// does not declare its length fails with an error TooLarge reports, so
+// the handler can answer 413 as well. A limit of 0 or less leaves bodies
+// unlimited.
+func LimitBody(limit int64) func(http.Handler) http.Handler {
+	return LimitBodyFunc(func(*http.Request) int64 { return limit })
+}
+
+// LimitBodyFunc is LimitBody with the limit looked up for every request,
+// so it can change while the server runs or depend on the request, such
+// as a larger limit for file uploads.
+func LimitBodyFunc(limit func(*http.Request) int64) func(http.Handler) http.Handler {
+	jsonResponder := response.NewDefaultJSONResponder()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := limit(r)
+			if limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > limit {
+				jsonResponder.Error(w, http.StatusRequestEntityTooLarge, message.NewErrorMessage(http.StatusRequestEntityTooLarge, "request body too large"))
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TooLarge reports whether err came from reading past the limit LimitBody
+// put on a request body.
+func TooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "http: request body too large")
+}
@@ -0,0 +1,64 @@
+package response
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// StatusRecorder records the status and size of a response for
+// middleware. It passes Flush and Hijack through for streamed responses
+// and websockets.
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status int
+	Bytes  int
+}
+
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+func (c *StatusRecorder) WriteHeader(status int) {
+	if c.Status == 0 {
+		c.Status = status
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *StatusRecorder) Write(b []byte) (int, error) {
+	if c.Status == 0 {
+		c.Status = http.StatusOK
+	}
+	n, err := c.ResponseWriter.Write(b)
+	c.Bytes += n
+	return n, err
+}
+
+func (c *StatusRecorder) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (c *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+
+	if c.Status == 0 {
+		c.Status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Code returns the recorded status, 200 when the handler wrote nothing.
+func (c *StatusRecorder) Code() int {
+	if c.Status == 0 {
+		return http.StatusOK
+	}
+
+	return c.Status
+}
@@ -0,0 +1,6 @@
+package response
+
+// StatusClientClosedRequest is the status nginx made up for a request the
+// client gave up on before the response was ready. The client never sees
+// it; it is for logs and metrics.
+const StatusClientClosedRequest = 499
@@ -0,0 +1,44 @@
+// Package logging carries a request-scoped logrus entry through the
+// context, so every layer logs with the id of the request it serves.
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey int
+
+const (
+	entryKey contextKey = iota
+	requestKey
+)
+
+// FromContext returns the logger of the request ctx belongs to, or the
+// standard logger outside of a request.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(entryKey).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// WithLogger returns a copy of ctx carrying entry.
+func WithLogger(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, entryKey, entry)
+}
+
+// RequestID returns the id of the request ctx belongs to, if any.
+func RequestID(ctx context.Context) string {
+	if ctx != nil {
+		if info, ok := ctx.Value(requestKey).(*requestInfo); ok {
+			return info.id
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/tracing"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Header carries the request id. A valid id sent by the client is kept,
+// so a request can be followed across services; otherwise one is made.
+// It is set on every response.
+const Header = "X-Request-ID"
+
+const maxRequestID = 128
+
+// requestInfo is shared between Middleware and Route: the route is only
+// known once the router matched the request, deeper in the chain.
+type requestInfo struct {
+	id    string
+	route string
+	user  string
+}
+
+// Middleware assigns the request id, puts a logger carrying it in the
+// request context and logs one line per request once it is served. It
+// wraps the whole router, so requests that match no route are logged
+// too; add Route to the router to log the matched route template. The raw
+// path is not logged, as paths such as the calendar feed carry a secret.
+// Run it inside tracing.Middleware for the logger to carry the trace id
+// as well.
+func Middleware(logger *logrus.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			info := &requestInfo{id: r.Header.Get(Header)}
+			if !validRequestID(info.id) {
+				info.id = newRequestID()
+			}
+			w.Header().Set(Header, info.id)
+
+			entry := logger.WithField("request_id", info.id)
+			if traceID := tracing.TraceID(r.Context()); traceID != "" {
+				entry = entry.WithField("trace_id", traceID)
+			}
+			ctx := context.WithValue(r.Context(), requestKey, info)
+			ctx = WithLogger(ctx, entry)
+
+			rw := response.NewStatusRecorder(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			fields := logrus.Fields{
+				"method":      r.Method,
+				"route":       info.route,
+				"status":      rw.Code(),
+				"bytes":       rw.Bytes,
+				"latency_ms":  float64(time.Since(start).Microseconds()) / 1000,
+				"remote_addr": r.RemoteAddr,
+				"user_agent":  r.UserAgent(),
+			}
+			if info.user != "" {
+				fields["user"] = info.user
+			}
+
+			line := entry.WithFields(fields)
+			if rw.Code() >= http.StatusInternalServerError {
+				line.Error("request")
+				return
+			}
+			line.Info("request")
+		})
+	}
+}
+
+// Route records the template of the matched route for Middleware. Add it
+// to the router with Use.
+func Route(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info, ok := r.Context().Value(requestKey).(*requestInfo); ok {
+			if route := mux.CurrentRoute(r); route != nil {
+				info.route, _ = route.GetPathTemplate()
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetUser records the user a handler authenticated for the request ctx
+// belongs to, for Middleware to log. Names the client merely claims, such
+// as a basic auth user nobody checked, must not be passed.
+func SetUser(ctx context.Context, user string) {
+	if info, ok := ctx.Value(requestKey).(*requestInfo); ok {
+		info.user = user
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMiddlewareLogsRouteAndAuthenticatedUser(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &out
+	logger.Formatter = &logrus.JSONFormatter{}
+
+	r := mux.NewRouter()
+	r.Use(Route)
+	r.HandleFunc("/calendar/{token}.ics", func(w http.ResponseWriter, r *http.Request) {})
+	r.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		SetUser(r.Context(), "alice")
+	})
+	handler := Middleware(logger)(r)
+
+	tests := []struct {
+		target string
+		route  string
+		user   string
+	}{
+		{"/calendar/s3cret.ics?user=mallory", "/calendar/{token}.ics", ""},
+		{"/me", "/me", "alice"},
+	}
+	for _, test := range tests {
+		out.Reset()
+		req := httptest.NewRequest(http.MethodGet, test.target, nil)
+		req.SetBasicAuth("mallory", "password")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if strings.Contains(out.String(), "s3cret") || strings.Contains(out.String(), "mallory") {
+			t.Errorf("%s: log line holds the token or an unauthenticated user: %s", test.target, out.String())
+		}
+
+		var line map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		if line["route"] != test.route {
+			t.Errorf("%s: route = %v, want %s", test.target, line["route"], test.route)
+		}
+		if user, _ := line["user"].(string); user != test.user {
+			t.Errorf("%s: user = %q, want %q", test.target, user, test.user)
+		}
+	}
+}
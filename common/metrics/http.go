@@ -0,0 +1,70 @@
+// Package metrics holds the Prometheus collectors shared by services.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTP measures the requests a router serves. Requests are labelled by
+// route template rather than path, so ids do not each make a series.
+type HTTP struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+func NewHTTP(reg prometheus.Registerer, namespace string) *HTTP {
+	c := &HTTP{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests served, by method, route template and status code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "code"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests being served, open websockets included.",
+		}),
+	}
+
+	reg.MustRegister(c.requests, c.duration, c.inFlight)
+
+	return c
+}
+
+// Middleware measures a request. Add it to the router with Use, so the
+// matched route is known; requests that match no route are not counted.
+func (c *HTTP) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		c.inFlight.Inc()
+		defer c.inFlight.Dec()
+
+		route := ""
+		if current := mux.CurrentRoute(r); current != nil {
+			route, _ = current.GetPathTemplate()
+		}
+
+		rw := response.NewStatusRecorder(w)
+		next.ServeHTTP(rw, r)
+
+		code := strconv.Itoa(rw.Code())
+		c.requests.WithLabelValues(r.Method, route, code).Inc()
+		c.duration.WithLabelValues(r.Method, route, code).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const Version = "3.0.3"
+
+// Document is the subset of the OpenAPI 3 object model used to describe
+// the services in this repository.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps a lower-case HTTP method to its operation. Methods
+// without an OpenAPI field are keyed x-<method>, as in x-propfind.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes a request body. A Streamed body is left to the
+// handler: ValidateRequest neither buffers nor validates it.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+	Streamed bool                 `json:"-"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+
+	// AdditionalProperties describes the values of a map-like object.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+}
+
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   title,
+			Version: version,
+		},
+		Paths: make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+}
+
+// Add registers an operation for a path template and method.
+func (c *Document) Add(path, method string, op *Operation) {
+	item, ok := c.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		c.Paths[path] = item
+	}
+
+	(*item)[methodKey(method)] = op
+}
+
+func (c *Document) Operation(path, method string) *Operation {
+	item, ok := c.Paths[path]
+	if !ok {
+		return nil
+	}
+
+	return (*item)[methodKey(method)]
+}
+
+func (c *Document) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	content, _ := json.Marshal(c)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func Int(v int) *int {
+	return &v
+}
@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CheckRoutes returns an error listing every route registered on r that
+// has no operation in the document. A route that matches any method
+// cannot be documented, so it is listed as "* template".
+func (c *Document) CheckRoutes(r *mux.Router) error {
+	missing := make([]string, 0)
+
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		// The route of a subrouter only holds its routes, which are
+		// walked on their own.
+		if route.GetHandler() == nil {
+			return nil
+		}
+
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			missing = append(missing, "route without a path")
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			missing = append(missing, "* "+template)
+			return nil
+		}
+
+		for _, method := range methods {
+			if c.Operation(template, method) == nil {
+				missing = append(missing, method+" "+template)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes missing from the openapi document: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ardiantirta/todo-crud/common/http/request"
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/message"
+	"github.com/gorilla/mux"
+)
+
+// ValidateRequest is a mux middleware that rejects JSON bodies that do not
+// match the request body schema of the matched operation.
+func (c *Document) ValidateRequest(next http.Handler) http.Handler {
+	jsonResponder := response.NewDefaultJSONResponder()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := c.operationFor(r)
+		if op == nil || op.RequestBody == nil || op.RequestBody.Streamed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		media, ok := op.RequestBody.Content[response.JSONContentType]
+		if !ok || media.Schema == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if request.TooLarge(err) {
+			jsonResponder.Error(w, http.StatusRequestEntityTooLarge, message.NewErrorMessage(http.StatusRequestEntityTooLarge, "request body too large"))
+			return
+		}
+		if err != nil {
+			jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "invalid json body"))
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) == 0 && !op.RequestBody.Required {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "invalid json body"))
+			return
+		}
+
+		if err := c.Validate(media.Schema, value); err != nil {
+			jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *Document) operationFor(r *http.Request) *Operation {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return nil
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return nil
+	}
+
+	return c.Operation(template, r.Method)
+}
+
+// Validate checks value, as decoded by encoding/json, against schema.
+func (c *Document) Validate(schema *Schema, value interface{}) error {
+	return c.validate("body", schema, value)
+}
+
+func (c *Document) validate(path string, schema *Schema, value interface{}) error {
+	if schema.Ref != "" {
+		resolved, ok := c.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", path, schema.Ref)
+		}
+		schema = resolved
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return fmt.Errorf("%s should not be null", path)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s should be an object", path)
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s is required", join(path, name))
+			}
+		}
+
+		for name, property := range schema.Properties {
+			v, ok := object[name]
+			if !ok {
+				continue
+			}
+
+			if err := c.validate(join(path, name), property, v); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s should be an array", path)
+		}
+
+		if schema.Items != nil {
+			for i, item := range items {
+				if err := c.validate(fmt.Sprintf("%s[%d]", path, i), schema.Items, item); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s should be a string", path)
+		}
+
+		length := utf8.RuneCountInString(s)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return fmt.Errorf("%s should be at least %d characters", path, *schema.MinLength)
+		}
+
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return fmt.Errorf("%s should be at most %d characters", path, *schema.MaxLength)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s should be an integer", path)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s should be a number", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s should be a boolean", path)
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if allowed == value {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%s should be one of %v", path, schema.Enum)
+	}
+
+	return nil
+}
+
+func join(path, name string) string {
+	if path == "body" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// methodKey is the key of method in a PathItem. Methods OpenAPI has no
+// field for, such as WebDAV's PROPFIND, go under an x- extension key so
+// the document stays valid.
+func methodKey(method string) string {
+	switch method = strings.ToLower(method); method {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return method
+	}
+
+	return "x-" + method
+}
@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardiantirta/todo-crud/common/http/request"
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/gorilla/mux"
+)
+
+func TestValidateRequest(t *testing.T) {
+	doc := New("test", "1.0.0")
+	doc.Add("/todo", http.MethodPost, &Operation{
+		OperationID: "createTodo",
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				response.JSONContentType: {Schema: &Schema{
+					Type:       "object",
+					Required:   []string{"title"},
+					Properties: map[string]*Schema{"title": {Type: "string"}},
+				}},
+			},
+		},
+	})
+
+	r := mux.NewRouter()
+	r.Use(request.LimitBody(32), doc.ValidateRequest)
+	r.HandleFunc("/todo", func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	}).Methods(http.MethodPost)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `{"title":"buy milk"}`, http.StatusOK},
+		{"invalid json", `{"title":`, http.StatusBadRequest},
+		{"schema", `{"title":1}`, http.StatusBadRequest},
+		{"too large", `{"title":"` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/todo", strings.NewReader(tt.body))
+		// An unknown length gets past the Content-Length check, so the
+		// limit is only hit while the body is read.
+		req.ContentLength = -1
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, w.Code, tt.status, w.Body)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package secure adds the security headers every response should carry.
+package secure
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// HSTSMaxAge is how long browsers should only use HTTPS. It is sent
+	// only on requests that came over HTTPS, directly or through a proxy
+	// setting X-Forwarded-Proto. 0 leaves HSTS out.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	// FrameOptions is DENY or SAMEORIGIN. Empty leaves the header out.
+	FrameOptions string
+	// ContentSecurityPolicy goes on HTML responses that do not set their
+	// own. Empty leaves it out.
+	ContentSecurityPolicy string
+	ReferrerPolicy        string
+}
+
+// Middleware sets the headers before next writes the response, so a
+// handler can still override them.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge/time.Second))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Set("X-Content-Type-Options", "nosniff")
+			if cfg.FrameOptions != "" {
+				header.Set("X-Frame-Options", cfg.FrameOptions)
+			}
+			if cfg.ReferrerPolicy != "" {
+				header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+			}
+			if hsts != "" && (r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")) {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+
+			if cfg.ContentSecurityPolicy != "" {
+				w = &htmlWriter{ResponseWriter: w, csp: cfg.ContentSecurityPolicy}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// htmlWriter adds the content security policy once the handler has set
+// the content type and it turns out to be HTML. It passes Flush and
+// Hijack through for streamed responses and websockets.
+type htmlWriter struct {
+	http.ResponseWriter
+	csp         string
+	wroteHeader bool
+}
+
+func (c *htmlWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		header := c.Header()
+		if strings.HasPrefix(header.Get("Content-Type"), "text/html") && header.Get("Content-Security-Policy") == "" {
+			header.Set("Content-Security-Policy", c.csp)
+		}
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *htmlWriter) Write(b []byte) (int, error) {
+	if !c.wroteHeader {
+		if c.Header().Get("Content-Type") == "" {
+			c.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		c.WriteHeader(http.StatusOK)
+	}
+
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *htmlWriter) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (c *htmlWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+
+	return h.Hijack()
+}
@@ -0,0 +1,76 @@
+package secure
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testConfig = Config{
+	HSTSMaxAge:            time.Hour,
+	HSTSIncludeSubdomains: true,
+	FrameOptions:          "DENY",
+	ContentSecurityPolicy: "default-src 'self'",
+	ReferrerPolicy:        "same-origin",
+}
+
+func TestHSTSOnlyOverTLS(t *testing.T) {
+	handler := Middleware(testConfig)(http.NotFoundHandler())
+
+	tests := []struct {
+		name  string
+		setup func(r *http.Request)
+		want  string
+	}{
+		{"plain http", func(r *http.Request) {}, ""},
+		{"tls", func(r *http.Request) { r.TLS = &tls.ConnectionState{} }, "max-age=3600; includeSubDomains"},
+		{"behind a tls proxy", func(r *http.Request) { r.Header.Set("X-Forwarded-Proto", "HTTPS") }, "max-age=3600; includeSubDomains"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		test.setup(r)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Strict-Transport-Security"); got != test.want {
+			t.Errorf("%s: Strict-Transport-Security = %q, want %q", test.name, got, test.want)
+		}
+		if w.Header().Get("X-Content-Type-Options") != "nosniff" || w.Header().Get("X-Frame-Options") != "DENY" || w.Header().Get("Referrer-Policy") != "same-origin" {
+			t.Errorf("%s: headers = %v", test.name, w.Header())
+		}
+	}
+}
+
+func TestCSPOnlyOnHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"html", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+		}, "default-src 'self'"},
+		{"sniffed html", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<!DOCTYPE html><html></html>"))
+		}, "default-src 'self'"},
+		{"json", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data": "<html>"}`))
+		}, ""},
+		{"own policy", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Security-Policy", "default-src 'none'")
+			w.WriteHeader(http.StatusOK)
+		}, "default-src 'none'"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		Middleware(testConfig)(test.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if got := w.Header().Get("Content-Security-Policy"); got != test.want {
+			t.Errorf("%s: Content-Security-Policy = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/standard"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+const (
+	contextSetting = "tracing:context"
+	spanSetting    = "tracing:span"
+)
+
+// WithContext returns a copy of db whose queries are traced as children of
+// the span in ctx. gorm has no context of its own, so the repository
+// passes it this way.
+func WithContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	return db.Set(contextSetting, ctx)
+}
+
+// RegisterCallbacks traces every create, query, update and delete run
+// through callback with its SQL, as in db.Callback(). Queries without a
+// context from WithContext, such as migrations, are not traced.
+func RegisterCallbacks(callback *gorm.Callback) {
+	callback.Create().Before("gorm:create").Register("tracing:before_create", before("gorm.create"))
+	callback.Create().After("gorm:create").Register("tracing:after_create", after)
+	callback.Query().Before("gorm:query").Register("tracing:before_query", before("gorm.query"))
+	callback.Query().After("gorm:query").Register("tracing:after_query", after)
+	callback.RowQuery().Before("gorm:row_query").Register("tracing:before_row_query", before("gorm.row_query"))
+	callback.RowQuery().After("gorm:row_query").Register("tracing:after_row_query", after)
+	callback.Update().Before("gorm:update").Register("tracing:before_update", before("gorm.update"))
+	callback.Update().After("gorm:update").Register("tracing:after_update", after)
+	callback.Delete().Before("gorm:delete").Register("tracing:before_delete", before("gorm.delete"))
+	callback.Delete().After("gorm:delete").Register("tracing:after_delete", after)
+}
+
+func before(name string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		value, ok := scope.Get(contextSetting)
+		if !ok {
+			return
+		}
+		ctx, ok := value.(context.Context)
+		if !ok {
+			return
+		}
+
+		_, span := global.Tracer(tracerName).Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(standard.DBTypeKey.String(scope.Dialect().GetName())),
+		)
+		scope.InstanceSet(spanSetting, span)
+	}
+}
+
+func after(scope *gorm.Scope) {
+	value, ok := scope.InstanceGet(spanSetting)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(standard.DBStatementKey.String(scope.SQL))
+	var err error
+	if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+		err = scope.DB().Error
+	}
+	End(context.Background(), span, err)
+}
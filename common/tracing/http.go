@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/propagation"
+	"go.opentelemetry.io/otel/api/standard"
+	"go.opentelemetry.io/otel/api/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// Middleware starts a server span for every request, continuing the trace
+// of the caller when it sends a traceparent header. It wraps the whole
+// router, so requests that match no route are traced too; add Route to
+// the router to name spans after the matched route template. The request
+// target is not recorded, as paths such as the calendar feed carry a
+// secret; http.route holds the template instead.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagation.ExtractHTTP(r.Context(), global.Propagators(), r.Header)
+		ctx, span := global.Tracer(tracerName).Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				standard.HTTPMethodKey.String(r.Method),
+				standard.HTTPUserAgentKey.String(r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		rw := response.NewStatusRecorder(w)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		span.SetAttributes(standard.HTTPStatusCodeKey.Int(rw.Code()))
+		if rw.Code() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Internal, http.StatusText(rw.Code()))
+		}
+	})
+}
+
+// Route names the request span after the matched route template. Add it
+// to the router with Use.
+func Route(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				span := trace.SpanFromContext(r.Context())
+				span.SetName(r.Method + " " + template)
+				span.SetAttributes(standard.HTTPRouteKey.String(template))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+)
+
+// Recorder is the memory exporter: it keeps every finished span so tests
+// can look at them.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*export.SpanData
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (c *Recorder) ExportSpan(ctx context.Context, data *export.SpanData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.spans = append(c.spans, data)
+}
+
+// Spans returns the spans recorded so far, in the order they ended.
+func (c *Recorder) Spans() []*export.SpanData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	spans := make([]*export.SpanData, len(c.spans))
+	copy(spans, c.spans)
+	return spans
+}
+
+// Reset forgets the recorded spans.
+func (c *Recorder) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.spans = nil
+}
@@ -0,0 +1,128 @@
+// Package tracing sets up OpenTelemetry and gives every layer a way to
+// start spans. Trace context travels in the W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/propagation"
+	"go.opentelemetry.io/otel/api/standard"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/trace/stdout"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// Exporters Setup knows. ExporterNone keeps the no-op tracer, so spans
+// cost next to nothing when tracing is off.
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterMemory = "memory"
+)
+
+const tracerName = "github.com/ardiantirta/todo-crud"
+
+type Config struct {
+	// Exporter is one of the Exporter constants.
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector. Empty means the
+	// collector default, localhost:55680.
+	Endpoint string
+	// SampleRatio is the fraction of new traces recorded. Traces started
+	// by a sampled caller are always recorded.
+	SampleRatio float64
+	ServiceName string
+	// Recorder receives the spans of the memory exporter.
+	Recorder *Recorder
+}
+
+// Setup installs the tracer provider and the W3C propagator globally.
+// The returned function flushes pending spans and must be called before
+// exiting.
+func Setup(cfg Config) (func(), error) {
+	global.SetPropagators(propagation.New(
+		propagation.WithExtractors(trace.TraceContext{}),
+		propagation.WithInjectors(trace.TraceContext{}),
+	))
+
+	var processor sdktrace.SpanProcessor
+	stop := func() {}
+	switch cfg.Exporter {
+	case ExporterNone:
+		return func() {}, nil
+	case ExporterOTLP:
+		opts := []otlp.ExporterOption{otlp.WithInsecure()}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlp.WithAddress(cfg.Endpoint))
+		}
+		exporter, err := otlp.NewExporter(opts...)
+		if err != nil {
+			return nil, err
+		}
+		batcher, err := sdktrace.NewBatchSpanProcessor(exporter)
+		if err != nil {
+			return nil, err
+		}
+		processor = batcher
+		stop = func() { _ = exporter.Stop() }
+	case ExporterStdout:
+		exporter, err := stdout.NewExporter(stdout.Options{})
+		if err != nil {
+			return nil, err
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	case ExporterMemory:
+		if cfg.Recorder == nil {
+			return nil, fmt.Errorf("memory trace exporter needs a recorder")
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(cfg.Recorder)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	provider, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.ProbabilitySampler(cfg.SampleRatio)}),
+		sdktrace.WithResource(resource.New(standard.ServiceNameKey.String(cfg.ServiceName))),
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider.RegisterSpanProcessor(processor)
+	global.SetTraceProvider(provider)
+
+	return func() {
+		provider.UnregisterSpanProcessor(processor)
+		stop()
+	}, nil
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...kv.KeyValue) (context.Context, trace.Span) {
+	return global.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err)
+		span.SetStatus(codes.Unknown, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the id of the trace ctx belongs to, if it is traced.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanFromContext(ctx).SpanContext()
+	if !sc.IsValid() {
+		return ""
+	}
+
+	return sc.TraceID.String()
+}
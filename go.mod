@@ -3,12 +3,19 @@ module github.com/ardiantirta/todo-crud
 go 1.13
 
 require (
+	github.com/fsnotify/fsnotify v1.4.7
 	github.com/go-playground/validator/v10 v10.2.0
-	github.com/gorilla/handlers v1.4.2
+	github.com/golang/protobuf v1.3.5
 	github.com/gorilla/mux v1.7.3
+	github.com/gorilla/websocket v1.4.2
+	github.com/graphql-go/graphql v0.8.1
 	github.com/jinzhu/gorm v1.9.12
 	github.com/lib/pq v1.2.0 // indirect
+	github.com/prometheus/client_golang v1.5.1
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/viper v1.4.0
-	golang.org/x/sys v0.0.0-20190813064441-fde4db37ae7a // indirect
+	go.opentelemetry.io/otel v0.6.0
+	go.opentelemetry.io/otel/exporters/otlp v0.6.0
+	golang.org/x/sys v0.0.0-20200122134326-e047566fdf82
+	google.golang.org/grpc v1.27.1
 )
@@ -0,0 +1,234 @@
+// Package backup writes and reads portable archives of the todo data.
+//
+// An archive is newline-delimited JSON: a header line, one line per row
+// and an end line holding the row count and the sha256 of every line
+// before it. Archives may be gzip-compressed; Read detects that itself.
+//
+//	{"type":"header","header":{"format":"todo-crud-backup","version":1,...}}
+//	{"type":"todo","todo":{"ID":1,...}}
+//	{"type":"end","rows":1,"checksum":"sha256:..."}
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+const (
+	Format = "todo-crud-backup"
+
+	// Version is the version of the archive layout.
+	Version = 1
+
+	// SchemaVersion is the version of the row layout. Archives from a
+	// newer schema are refused.
+	SchemaVersion = 1
+
+	typeHeader = "header"
+	typeTodo   = "todo"
+	typeEnd    = "end"
+)
+
+var (
+	ErrTruncated = errors.New("backup archive is truncated")
+	ErrChecksum  = errors.New("backup archive checksum mismatch")
+)
+
+type Header struct {
+	Format        string    `json:"format"`
+	Version       int       `json:"version"`
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Entities      []string  `json:"entities"`
+}
+
+// Summary describes an archive that was written or restored.
+type Summary struct {
+	Header   Header
+	Rows     int
+	Checksum string
+}
+
+type line struct {
+	Type     string             `json:"type"`
+	Header   *Header            `json:"header,omitempty"`
+	Todo     *todo.ViewResponse `json:"todo,omitempty"`
+	Rows     int                `json:"rows,omitempty"`
+	Checksum string             `json:"checksum,omitempty"`
+}
+
+// Write dumps every todo, soft-deleted ones included, to w.
+func Write(ctx context.Context, repo repository.Repository, w io.Writer, compress bool) (*Summary, error) {
+	if compress {
+		zw := gzip.NewWriter(w)
+		summary, err := Write(ctx, repo, zw, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return summary, zw.Close()
+	}
+
+	bw := bufio.NewWriter(w)
+	sum := sha256.New()
+	summary := &Summary{
+		Header: Header{
+			Format:        Format,
+			Version:       Version,
+			SchemaVersion: SchemaVersion,
+			CreatedAt:     time.Now().UTC(),
+			Entities:      []string{typeTodo},
+		},
+	}
+
+	if err := writeLine(bw, sum, &line{Type: typeHeader, Header: &summary.Header}); err != nil {
+		return nil, err
+	}
+
+	err := repo.Dump(ctx, func(data *todo.ViewResponse) error {
+		summary.Rows++
+		return writeLine(bw, sum, &line{Type: typeTodo, Todo: data})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Checksum = "sha256:" + hex.EncodeToString(sum.Sum(nil))
+	if err := writeLine(bw, nil, &line{Type: typeEnd, Rows: summary.Rows, Checksum: summary.Checksum}); err != nil {
+		return nil, err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func writeLine(w io.Writer, sum hash.Hash, l *line) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	if sum != nil {
+		sum.Write(b)
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// Read restores an archive into an empty database. Nothing is kept
+// unless the whole archive is read and its checksum matches.
+func Read(ctx context.Context, repo repository.Repository, r io.Reader) (*Summary, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		br = bufio.NewReader(zr)
+	}
+
+	sum := sha256.New()
+	summary := new(Summary)
+
+	first, err := readLine(br, sum)
+	if err == io.EOF {
+		return nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, err
+	}
+	if first.Type != typeHeader || first.Header == nil || first.Header.Format != Format {
+		return nil, errors.New("not a todo-crud backup archive")
+	}
+	if first.Header.Version != Version {
+		return nil, fmt.Errorf("unsupported backup archive version %d", first.Header.Version)
+	}
+	if first.Header.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("backup archive has schema version %d, newer than %d", first.Header.SchemaVersion, SchemaVersion)
+	}
+	summary.Header = *first.Header
+
+	checksum := ""
+	err = repo.Restore(ctx, func() (*todo.ViewResponse, error) {
+		next, err := readLine(br, sum)
+		if err == io.EOF {
+			return nil, ErrTruncated
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch next.Type {
+		case typeTodo:
+			if next.Todo == nil {
+				return nil, fmt.Errorf("backup archive row %d is empty", summary.Rows+1)
+			}
+			summary.Rows++
+			return next.Todo, nil
+		case typeEnd:
+			checksum = "sha256:" + hex.EncodeToString(sum.Sum(nil))
+			if next.Rows != summary.Rows {
+				return nil, fmt.Errorf("backup archive has %d rows, its end line says %d", summary.Rows, next.Rows)
+			}
+			if next.Checksum != checksum {
+				return nil, ErrChecksum
+			}
+			if _, err := br.ReadByte(); err != io.EOF {
+				return nil, errors.New("backup archive has data after its end line")
+			}
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("unknown backup archive line type %q", next.Type)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Checksum = checksum
+
+	return summary, nil
+}
+
+// readLine reads one line and adds it to sum, except for the end line,
+// which holds the checksum itself.
+func readLine(r *bufio.Reader, sum hash.Hash) (*line, error) {
+	b, err := r.ReadBytes('\n')
+	if err == io.EOF && len(b) > 0 {
+		err = ErrTruncated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(line)
+	if err := json.Unmarshal(b, l); err != nil {
+		return nil, fmt.Errorf("invalid backup archive line: %s", err)
+	}
+
+	if l.Type != typeEnd {
+		sum.Write(b)
+	}
+
+	return l, nil
+}
@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/jinzhu/gorm"
+)
+
+// memoryRepository dumps and restores todos in memory. Restore keeps
+// nothing unless next ends with io.EOF, like the transaction of
+// TodoRepository.
+type memoryRepository struct {
+	repository.Repository
+	todos []todo.ViewResponse
+}
+
+func (c *memoryRepository) Dump(ctx context.Context, fn func(*todo.ViewResponse) error) error {
+	for i := range c.todos {
+		data := c.todos[i]
+		if err := fn(&data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memoryRepository) Restore(ctx context.Context, next func() (*todo.ViewResponse, error)) error {
+	if len(c.todos) > 0 {
+		return repository.ErrNotEmpty
+	}
+
+	restored := make([]todo.ViewResponse, 0)
+	for {
+		data, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		restored = append(restored, *data)
+	}
+
+	c.todos = restored
+	return nil
+}
+
+func sampleTodos() []todo.ViewResponse {
+	created := time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC)
+	deleted := created.Add(time.Hour)
+	return []todo.ViewResponse{
+		{Model: gorm.Model{ID: 1, CreatedAt: created, UpdatedAt: created}, Title: "buy milk", Description: "two liters of milk"},
+		{Model: gorm.Model{ID: 2, CreatedAt: created, UpdatedAt: deleted, DeletedAt: &deleted}, Title: "call mom", Description: "about the weekend", IsDone: true},
+		{Model: gorm.Model{ID: 5, CreatedAt: created, UpdatedAt: created}, Title: "water the plants", Description: "all of them", IsFavorite: true, ExternalID: "plants"},
+	}
+}
+
+func archive(t *testing.T, compress bool) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	summary, err := Write(context.Background(), &memoryRepository{todos: sampleTodos()}, &b, compress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Rows != 3 || !strings.HasPrefix(summary.Checksum, "sha256:") {
+		t.Fatalf("summary = %+v", summary)
+	}
+	return b.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		b := archive(t, compress)
+		if gzipped := bytes.HasPrefix(b, []byte{0x1f, 0x8b}); gzipped != compress {
+			t.Errorf("compress %v: archive gzipped = %v", compress, gzipped)
+		}
+
+		fresh := &memoryRepository{}
+		summary, err := Read(context.Background(), fresh, bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("compress %v: %s", compress, err)
+		}
+		if summary.Rows != 3 || summary.Header.SchemaVersion != SchemaVersion {
+			t.Errorf("compress %v: summary = %+v", compress, summary)
+		}
+
+		want := sampleTodos()
+		for i := range fresh.todos {
+			// JSON drops the monotonic clock reading, not the instant.
+			if !fresh.todos[i].CreatedAt.Equal(want[i].CreatedAt) {
+				t.Errorf("todo %d created at %s", i, fresh.todos[i].CreatedAt)
+			}
+			fresh.todos[i].CreatedAt, want[i].CreatedAt = time.Time{}, time.Time{}
+			fresh.todos[i].UpdatedAt, want[i].UpdatedAt = time.Time{}, time.Time{}
+		}
+		if fresh.todos[1].DeletedAt == nil || !fresh.todos[1].DeletedAt.Equal(*want[1].DeletedAt) {
+			t.Errorf("compress %v: soft-deleted todo restored as %+v", compress, fresh.todos[1])
+		}
+		fresh.todos[1].DeletedAt, want[1].DeletedAt = nil, nil
+		if !reflect.DeepEqual(fresh.todos, want) {
+			t.Errorf("compress %v: restored %+v, want %+v", compress, fresh.todos, want)
+		}
+	}
+}
+
+func TestReadRejectsBadArchives(t *testing.T) {
+	lines := strings.SplitAfter(string(archive(t, false)), "\n")
+	lines = lines[:len(lines)-1] // the empty string after the last newline
+
+	tampered := strings.Replace(strings.Join(lines, ""), "buy milk", "buy beer", 1)
+
+	var newer bytes.Buffer
+	var header line
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	header.Header.SchemaVersion = SchemaVersion + 1
+	b, _ := json.Marshal(header)
+	newer.Write(append(b, '\n'))
+	newer.WriteString(strings.Join(lines[1:], ""))
+
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	zw.Write([]byte(tampered))
+	zw.Close()
+
+	tests := []struct {
+		name    string
+		archive string
+		want    error
+		message string
+	}{
+		{name: "checksum mismatch", archive: tampered, want: ErrChecksum},
+		{name: "checksum mismatch gzipped", archive: gzipped.String(), want: ErrChecksum},
+		{name: "no end line", archive: strings.Join(lines[:len(lines)-1], ""), want: ErrTruncated},
+		{name: "cut mid line", archive: strings.Join(lines, "")[:len(lines[0])+10], want: ErrTruncated},
+		{name: "empty", archive: "", want: ErrTruncated},
+		{name: "newer schema", archive: newer.String(), message: "newer than"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &memoryRepository{}
+			_, err := Read(context.Background(), repo, strings.NewReader(test.archive))
+			if err == nil {
+				t.Fatal("archive was restored")
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Errorf("err = %v, want %v", err, test.want)
+			}
+			if test.message != "" && !strings.Contains(err.Error(), test.message) {
+				t.Errorf("err = %v, want it to mention %q", err, test.message)
+			}
+			if len(repo.todos) != 0 {
+				t.Errorf("%d todos were kept", len(repo.todos))
+			}
+		})
+	}
+}
+
+func TestReadNeedsAnEmptyDatabase(t *testing.T) {
+	_, err := Read(context.Background(), &memoryRepository{todos: sampleTodos()[:1]}, bytes.NewReader(archive(t, false)))
+	if err != repository.ErrNotEmpty {
+		t.Errorf("err = %v, want %v", err, repository.ErrNotEmpty)
+	}
+}
@@ -0,0 +1,377 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// Authenticator decorates outgoing requests with credentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) error
+}
+
+// BearerToken sends a static token in the Authorization header.
+type BearerToken string
+
+func (c BearerToken) Authenticate(r *http.Request) error {
+	r.Header.Set("Authorization", "Bearer "+string(c))
+	return nil
+}
+
+// APIError is an error body returned by the service.
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+func (c *APIError) Error() string {
+	return fmt.Sprintf("todo api: %d %s", c.StatusCode, c.Message)
+}
+
+// NotFoundError is returned when the todo does not exist.
+type NotFoundError struct {
+	*APIError
+}
+
+func (c *NotFoundError) Unwrap() error {
+	return c.APIError
+}
+
+// ValidationError is returned when the service rejected the request, such
+// as a title that is too short. Message says what is wrong.
+type ValidationError struct {
+	*APIError
+}
+
+func (c *ValidationError) Unwrap() error {
+	return c.APIError
+}
+
+// typedError returns err as the error type of its status, which callers
+// can check for with errors.As. Any error is an *APIError underneath.
+func typedError(err *APIError) error {
+	switch err.StatusCode {
+	case http.StatusNotFound:
+		return &NotFoundError{APIError: err}
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return &ValidationError{APIError: err}
+	}
+
+	return err
+}
+
+// Client is a typed client for the todo REST API. Idempotent requests
+// (GET, PUT, DELETE) are retried with exponential backoff on network errors
+// and 429/5xx responses. Error responses come back as *NotFoundError,
+// *ValidationError or otherwise *APIError.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Auth       Authenticator
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		MaxRetries: 3,
+		Backoff:    100 * time.Millisecond,
+	}
+}
+
+type GetAllParams struct {
+	IsDone     *bool
+	IsFavorite *bool
+	// Limit asks for at most Limit todos with an id above AfterID, in id
+	// order. 0 returns every todo at once.
+	Limit   int
+	AfterID int
+}
+
+func (c *Client) Create(ctx context.Context, form *todo.CreateRequest) (*todo.CreateResponse, error) {
+	resp := new(todo.CreateResponse)
+	if err := c.do(ctx, http.MethodPost, "/todo", nil, form, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetByID(ctx context.Context, id int) (*todo.ViewResponse, error) {
+	resp := new(todo.ViewResponse)
+	if err := c.do(ctx, http.MethodGet, "/todo/"+strconv.Itoa(id), nil, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Pages iterates over the todos matching params, size at a time, starting
+// after params.AfterID:
+//
+//	pages := c.Pages(client.GetAllParams{}, 50)
+//	for pages.Next(ctx) {
+//		for _, data := range pages.Page() { ... }
+//	}
+//	if err := pages.Err(); err != nil { ... }
+func (c *Client) Pages(params GetAllParams, size int) *Pages {
+	params.Limit = size
+	return &Pages{client: c, params: params}
+}
+
+// Pages is an iterator over the pages of GetAll.
+type Pages struct {
+	client *Client
+	params GetAllParams
+	page   []todo.ViewResponse
+	done   bool
+	err    error
+}
+
+// Next fetches the next page and reports whether there is one. It returns
+// false at the end or on an error, which Err then returns.
+func (c *Pages) Next(ctx context.Context) bool {
+	if c.done {
+		return false
+	}
+
+	page, err := c.client.GetAll(ctx, c.params)
+	if err != nil {
+		c.err, c.done, c.page = err, true, nil
+		return false
+	}
+	if len(page) == 0 {
+		c.done, c.page = true, nil
+		return false
+	}
+
+	// A short page is the last one, which saves asking for an empty page.
+	c.done = len(page) < c.params.Limit
+	c.params.AfterID = int(page[len(page)-1].ID)
+	c.page = page
+	return true
+}
+
+// Page returns the page fetched by the last call to Next.
+func (c *Pages) Page() []todo.ViewResponse {
+	return c.page
+}
+
+func (c *Pages) Err() error {
+	return c.err
+}
+
+func (c *Client) GetByTitle(ctx context.Context, title string) ([]todo.ViewResponse, error) {
+	resp := make([]todo.ViewResponse, 0)
+	if err := c.do(ctx, http.MethodGet, "/todo/search", url.Values{"title": {title}}, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetAll(ctx context.Context, params GetAllParams) ([]todo.ViewResponse, error) {
+	query := url.Values{}
+	if params.IsDone != nil {
+		query.Set("is_done", strconv.FormatBool(*params.IsDone))
+	}
+	if params.IsFavorite != nil {
+		query.Set("is_favorite", strconv.FormatBool(*params.IsFavorite))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+		query.Set("after_id", strconv.Itoa(params.AfterID))
+	}
+
+	resp := make([]todo.ViewResponse, 0)
+	if err := c.do(ctx, http.MethodGet, "/todo", query, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) UpdateData(ctx context.Context, id int, form *todo.UpdateRequest) (*todo.ViewResponse, error) {
+	resp := new(todo.ViewResponse)
+	if err := c.do(ctx, http.MethodPut, "/todo/"+strconv.Itoa(id), nil, form, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) MarkAsDone(ctx context.Context, id int, isDone bool) error {
+	form := &todo.DoneRequest{IsDone: strconv.FormatBool(isDone)}
+	return c.do(ctx, http.MethodPut, "/todo/done/"+strconv.Itoa(id), nil, form, nil)
+}
+
+func (c *Client) MarkAsFavorite(ctx context.Context, id int, isFavorite bool) error {
+	form := &todo.FavoriteRequest{IsFavorite: strconv.FormatBool(isFavorite)}
+	return c.do(ctx, http.MethodPut, "/todo/favorite/"+strconv.Itoa(id), nil, form, nil)
+}
+
+func (c *Client) DeleteByID(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/todo/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+func (c *Client) Changes(ctx context.Context, token string) (*todo.ChangesResponse, error) {
+	resp := new(todo.ChangesResponse)
+	if err := c.do(ctx, http.MethodGet, "/sync", url.Values{"since": {token}}, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) Sync(ctx context.Context, form *todo.SyncRequest) (*todo.SyncResponse, error) {
+	resp := new(todo.SyncResponse)
+	if err := c.do(ctx, http.MethodPost, "/sync", nil, form, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) SyncMarkdown(ctx context.Context, form *todo.MarkdownSyncRequest) (*todo.MarkdownSyncResponse, error) {
+	resp := new(todo.MarkdownSyncResponse)
+	if err := c.do(ctx, http.MethodPost, "/todo/markdown/sync", nil, form, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// do sends a request and decodes the data envelope of the response into
+// out. When out is nil the response body is discarded.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	retries := 0
+	if idempotent(method) {
+		retries = c.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.Backoff<<uint(attempt-1)); err != nil {
+				return err
+			}
+		}
+
+		retry, err := c.send(ctx, method, target, body, out)
+		if err == nil || !retry {
+			return err
+		}
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+func (c *Client) send(ctx context.Context, method, target string, body []byte, out interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", response.JSONContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", response.JSONContentType)
+	}
+
+	if c.Auth != nil {
+		if err := c.Auth.Authenticate(req); err != nil {
+			return false, err
+		}
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+		errorResponse := new(response.ErrorResponse)
+		if err := json.Unmarshal(content, errorResponse); err == nil && errorResponse.Message != "" {
+			apiErr.Code = errorResponse.Code
+			apiErr.Message = errorResponse.Message
+		}
+
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, typedError(apiErr)
+	}
+
+	if out == nil {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(content, &response.DataResponse{Data: out}); err != nil {
+		return false, fmt.Errorf("todo api: invalid response body: %v", err)
+	}
+
+	return false, nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,356 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	todoHttp "github.com/ardiantirta/todo-crud/services/todo/delivery/http"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+)
+
+// memoryRepository keeps todos in memory for the calls the REST handler
+// makes.
+type memoryRepository struct {
+	repository.Repository
+
+	mu     sync.Mutex
+	todos  map[uint]todo.ViewResponse
+	lastID uint
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{todos: make(map[uint]todo.ViewResponse)}
+}
+
+func (c *memoryRepository) Create(ctx context.Context, data *repository.Todo) (*todo.CreateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastID++
+	now := time.Now()
+	data.ID, data.CreatedAt, data.UpdatedAt = c.lastID, now, now
+	c.todos[data.ID] = todo.ViewResponse{
+		Model:       data.Model,
+		Title:       data.Title,
+		Description: data.Description,
+		IsDone:      data.IsDone,
+		IsFavorite:  data.IsFavorite,
+		ExternalID:  data.ExternalID,
+	}
+
+	resp := todo.CreateResponse(c.todos[data.ID])
+	return &resp, nil
+}
+
+func (c *memoryRepository) Save(ctx context.Context, data *todo.ViewResponse) (*todo.ViewResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data.UpdatedAt = time.Now()
+	c.todos[data.ID] = *data
+	return data, nil
+}
+
+func (c *memoryRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, ok := c.todos[uint(todoID)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &data, nil
+}
+
+func (c *memoryRepository) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	afterID, _ := params["after_id"].(int)
+	response := make([]todo.ViewResponse, 0)
+	for _, data := range c.todos {
+		if isDone := params["is_done"].(string); isDone != "" && isDone != strconv.FormatBool(data.IsDone) {
+			continue
+		}
+		if isFavorite := params["is_favorite"].(string); isFavorite != "" && isFavorite != strconv.FormatBool(data.IsFavorite) {
+			continue
+		}
+		if int(data.ID) > afterID {
+			response = append(response, data)
+		}
+	}
+	sort.Slice(response, func(i, j int) bool { return response[i].ID < response[j].ID })
+
+	if limit, ok := params["limit"].(int); ok && len(response) > limit {
+		response = response[:limit]
+	}
+	return response, nil
+}
+
+func (c *memoryRepository) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	all, _ := c.GetAll(ctx, map[string]interface{}{"is_done": "", "is_favorite": ""})
+
+	response := make([]todo.ViewResponse, 0)
+	for _, data := range all {
+		if strings.Contains(data.Title, params["title"].(string)) {
+			response = append(response, data)
+		}
+	}
+	return response, nil
+}
+
+func (c *memoryRepository) DeleteByID(ctx context.Context, todoID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.todos, uint(todoID))
+	return nil
+}
+
+// newServer serves NewTodoHandler over an empty memory repository until
+// stop is called. wrap, if not nil, sits in front of the handler.
+func newServer(wrap func(http.Handler) http.Handler) (c *Client, stop func()) {
+	repo := newMemoryRepository()
+	r := mux.NewRouter()
+	todoHttp.NewTodoHandler(r, service.NewTodoService(repo, event.NewBroker()))
+
+	var handler http.Handler = r
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	server := httptest.NewServer(handler)
+
+	c = NewClient(server.URL)
+	c.Backoff = time.Millisecond
+	return c, server.Close
+}
+
+func createTodos(t *testing.T, c *Client, titles ...string) []uint {
+	t.Helper()
+
+	ids := make([]uint, 0, len(titles))
+	for _, title := range titles {
+		created, err := c.Create(context.Background(), &todo.CreateRequest{Title: title, Description: "a description of " + title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	return ids
+}
+
+func TestCRUD(t *testing.T) {
+	ctx := context.Background()
+	c, stop := newServer(nil)
+	defer stop()
+
+	id := int(createTodos(t, c, "buy milk")[0])
+
+	got, err := c.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "buy milk" || got.IsDone {
+		t.Errorf("created todo = %+v", got)
+	}
+
+	updated, err := c.UpdateData(ctx, id, &todo.UpdateRequest{Title: "buy oat milk", Description: "two liters of oat milk", IsDone: "false", IsFavorite: "false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != "buy oat milk" {
+		t.Errorf("updated title = %q", updated.Title)
+	}
+
+	if err := c.MarkAsDone(ctx, id, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.MarkAsFavorite(ctx, id, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = c.GetByID(ctx, id); err != nil || !got.IsDone || !got.IsFavorite {
+		t.Errorf("after marking: %+v, %v", got, err)
+	}
+
+	found, err := c.GetByTitle(ctx, "oat")
+	if err != nil || len(found) != 1 || int(found[0].ID) != id {
+		t.Errorf("search = %+v, %v", found, err)
+	}
+
+	if err := c.DeleteByID(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetByID(ctx, id)
+
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("get after delete: %v, want a *NotFoundError", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("get after delete: %v, want a 404 *APIError", err)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	c, stop := newServer(nil)
+	defer stop()
+
+	_, err := c.Create(context.Background(), &todo.CreateRequest{Title: "x", Description: "a description"})
+
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("create: %v, want a *ValidationError", err)
+	}
+	if !strings.Contains(invalid.Message, "title") {
+		t.Errorf("message = %q, want the service's message about the title", invalid.Message)
+	}
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		t.Error("a validation error is also a *NotFoundError")
+	}
+}
+
+func TestPages(t *testing.T) {
+	ctx := context.Background()
+	c, stop := newServer(nil)
+	defer stop()
+
+	ids := createTodos(t, c, "one", "two", "three", "four", "five", "six", "seven")
+	if err := c.MarkAsDone(ctx, int(ids[1]), true); err != nil {
+		t.Fatal(err)
+	}
+
+	var sizes []int
+	var seen []uint
+	pages := c.Pages(GetAllParams{}, 3)
+	for pages.Next(ctx) {
+		sizes = append(sizes, len(pages.Page()))
+		for _, data := range pages.Page() {
+			seen = append(seen, data.ID)
+		}
+	}
+	if err := pages.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Errorf("page sizes = %v, want [3 3 1]", sizes)
+	}
+	for i := range ids {
+		if i >= len(seen) || seen[i] != ids[i] {
+			t.Fatalf("ids = %v, want %v", seen, ids)
+		}
+	}
+
+	notDone := false
+	pages = c.Pages(GetAllParams{IsDone: &notDone}, 2)
+	count := 0
+	for pages.Next(ctx) {
+		for _, data := range pages.Page() {
+			if data.IsDone {
+				t.Errorf("page holds done todo %d", data.ID)
+			}
+			count++
+		}
+	}
+	if count != len(ids)-1 || pages.Err() != nil {
+		t.Errorf("filtered pages held %d todos, %v; want %d", count, pages.Err(), len(ids)-1)
+	}
+}
+
+func TestPagesStopOnError(t *testing.T) {
+	c, stop := newServer(nil)
+	defer stop()
+	c.BaseURL += "/missing"
+
+	pages := c.Pages(GetAllParams{}, 10)
+	if pages.Next(context.Background()) {
+		t.Fatal("Next found a page")
+	}
+	var notFound *NotFoundError
+	if !errors.As(pages.Err(), &notFound) {
+		t.Errorf("Err = %v, want a *NotFoundError", pages.Err())
+	}
+}
+
+func TestRetriesIdempotentRequests(t *testing.T) {
+	var mu sync.Mutex
+	failures, calls := 0, map[string]int{}
+
+	c, stop := newServer(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls[r.Method]++
+			fail := failures > 0
+			if fail {
+				failures--
+			}
+			mu.Unlock()
+
+			if fail {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	defer stop()
+
+	failures = 2
+	if _, err := c.GetAll(context.Background(), GetAllParams{}); err != nil {
+		t.Errorf("GET after two failures: %v", err)
+	}
+	if calls[http.MethodGet] != 3 {
+		t.Errorf("GET sent %d times, want 3", calls[http.MethodGet])
+	}
+
+	failures = 1
+	_, err := c.Create(context.Background(), &todo.CreateRequest{Title: "buy milk", Description: "two liters of milk"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("POST: %v, want a 503 *APIError", err)
+	}
+	if calls[http.MethodPost] != 1 {
+		t.Errorf("POST sent %d times, want 1", calls[http.MethodPost])
+	}
+}
+
+func TestAuthAndContext(t *testing.T) {
+	var mu sync.Mutex
+	var authorization string
+	c, stop := newServer(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			authorization = r.Header.Get("Authorization")
+			mu.Unlock()
+			next.ServeHTTP(w, r)
+		})
+	})
+	defer stop()
+	c.Auth = BearerToken("secret")
+
+	if _, err := c.GetAll(context.Background(), GetAllParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Authorization = %q", authorization)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetAll(ctx, GetAllParams{}); err != context.Canceled {
+		t.Errorf("canceled call: %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,139 @@
+// Package codec reads and writes todos in the file formats used by the
+// import and export endpoints.
+package codec
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatTodoTxt  = "todotxt"
+	FormatICS      = "ics"
+	FormatMarkdown = "markdown"
+)
+
+// Fields lists the todo fields read on import.
+var Fields = []string{"external_id", "title", "description", "is_done", "is_favorite"}
+
+func IsField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Record is a todo read from an import file. Row is its 1-based position
+// in the file, not counting a header. CreatedAt is only set by formats
+// that carry it and is kept when the record creates a todo.
+type Record struct {
+	Row         int
+	ExternalID  string
+	Title       string
+	Description string
+	IsDone      bool
+	IsFavorite  bool
+	CreatedAt   time.Time
+}
+
+// RowError reports a row that could not be read. Decoding can continue
+// with the next row.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+type Encoder interface {
+	Encode(data *todo.ViewResponse) error
+	// Close writes anything the format needs after the last todo.
+	Close() error
+}
+
+// Decoder returns io.EOF after the last record. A *RowError only affects
+// its row; any other error ends decoding.
+type Decoder interface {
+	Decode() (*Record, error)
+}
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatTodoTxt:
+		return "text/plain; charset=utf-8"
+	case FormatICS:
+		return "text/calendar; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// FileName returns the name an export in format is saved under.
+func FileName(format string) string {
+	switch format {
+	case FormatTodoTxt:
+		return "todo.txt"
+	case FormatMarkdown:
+		return "todos.md"
+	}
+
+	return "todos." + format
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w)
+	case FormatJSON:
+		return &jsonEncoder{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{w: w}, nil
+	case FormatTodoTxt:
+		return &todoTxtEncoder{w: w}, nil
+	case FormatICS:
+		return &icsEncoder{w: w}, nil
+	case FormatMarkdown:
+		return &markdownEncoder{w: w}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// NewDecoder returns a decoder for format. mapping renames CSV columns: it
+// maps a field name such as "title" to the header used in the file.
+func NewDecoder(format string, r io.Reader, mapping map[string]string) (Decoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVDecoder(r, mapping)
+	case FormatJSON:
+		return newJSONDecoder(r)
+	case FormatNDJSON:
+		return newNDJSONDecoder(r), nil
+	case FormatTodoTxt:
+		return newTodoTxtDecoder(r), nil
+	case FormatICS:
+		return newICSDecoder(r), nil
+	case FormatMarkdown:
+		return newMarkdownDecoder(r)
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
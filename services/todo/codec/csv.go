@@ -0,0 +1,178 @@
+package codec
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+var csvHeader = []string{"id", "external_id", "title", "description", "is_done", "is_favorite", "created_at", "updated_at"}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	c := &csvEncoder{w: csv.NewWriter(w)}
+	if err := c.w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *csvEncoder) Encode(data *todo.ViewResponse) error {
+	return c.w.Write([]string{
+		strconv.FormatUint(uint64(data.ID), 10),
+		data.ExternalID,
+		csvText(data.Title),
+		csvText(data.Description),
+		strconv.FormatBool(data.IsDone),
+		strconv.FormatBool(data.IsFavorite),
+		data.CreatedAt.Format(time.RFC3339),
+		data.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// csvFormulaPrefixes start a cell that spreadsheets evaluate as a formula.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText keeps spreadsheets from running text as a formula by prefixing
+// it with a quote, which they show as plain text. Import removes the
+// quote again.
+func csvText(s string) string {
+	if s != "" && strings.IndexByte(csvFormulaPrefixes, s[0]) >= 0 {
+		return "'" + s
+	}
+
+	return s
+}
+
+// csvUnquote undoes csvText.
+func csvUnquote(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.IndexByte(csvFormulaPrefixes, s[1]) >= 0 {
+		return s[1:]
+	}
+
+	return s
+}
+
+func (c *csvEncoder) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type csvDecoder struct {
+	r       *csv.Reader
+	row     int
+	columns map[string]int
+}
+
+func newCSVDecoder(r io.Reader, mapping map[string]string) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %s", err)
+	}
+
+	// Excel starts a CSV saved as UTF-8 with a byte order mark.
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+
+	columns := make(map[string]int)
+	for _, field := range Fields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+
+		if i, ok := index[name]; ok {
+			columns[field] = i
+		}
+	}
+
+	for field := range mapping {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("column %q for %s is not in the csv header", mapping[field], field)
+		}
+	}
+
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("csv header has no title column")
+	}
+
+	return &csvDecoder{r: reader, columns: columns}, nil
+}
+
+func (c *csvDecoder) Decode() (*Record, error) {
+	fields, err := c.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	c.row++
+	if err != nil {
+		if _, ok := err.(*csv.ParseError); ok {
+			return nil, &RowError{Row: c.row, Err: err}
+		}
+		return nil, err
+	}
+
+	value := func(field string) string {
+		i, ok := c.columns[field]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return fields[i]
+	}
+
+	record := &Record{
+		Row:         c.row,
+		ExternalID:  strings.TrimSpace(value("external_id")),
+		Title:       csvUnquote(value("title")),
+		Description: csvUnquote(value("description")),
+	}
+
+	if record.IsDone, err = parseBool(value("is_done")); err != nil {
+		return nil, &RowError{Row: c.row, Err: fmt.Errorf("is_done: %s", err)}
+	}
+	if record.IsFavorite, err = parseBool(value("is_favorite")); err != nil {
+		return nil, &RowError{Row: c.row, Err: fmt.Errorf("is_favorite: %s", err)}
+	}
+
+	return record, nil
+}
+
+// parseBool accepts what strconv.ParseBool does and treats an empty cell
+// as false.
+func parseBool(s string) (bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("%q is not a boolean", s)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,141 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/jinzhu/gorm"
+)
+
+// decodeAll reads every record, collecting row errors.
+func decodeAll(t *testing.T, dec Decoder) ([]*Record, []*RowError, error) {
+	t.Helper()
+
+	var records []*Record
+	var rowErrs []*RowError
+	for {
+		record, err := dec.Decode()
+		if err == io.EOF {
+			return records, rowErrs, nil
+		}
+		if rowErr, ok := err.(*RowError); ok {
+			rowErrs = append(rowErrs, rowErr)
+			continue
+		}
+		if err != nil {
+			return records, rowErrs, err
+		}
+		records = append(records, record)
+	}
+}
+
+func TestCSVColumnMapping(t *testing.T) {
+	const file = "\ufeffTask,Notes,Done,Ref\n" +
+		"buy milk,two liters,yes,\n" +
+		"call mom,,TRUE, mom \n"
+
+	dec, err := NewDecoder(FormatCSV, strings.NewReader(file), map[string]string{
+		"title":       "Task",
+		"description": "Notes",
+		"is_done":     "Done",
+		"external_id": "Ref",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rowErrs, err := decodeAll(t, dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rowErrs) != 1 || rowErrs[0].Row != 1 || !strings.Contains(rowErrs[0].Error(), "is_done") {
+		t.Errorf("row errors = %v, want one for the is_done of row 1", rowErrs)
+	}
+	if len(records) != 1 {
+		t.Fatalf("records = %+v", records)
+	}
+	want := Record{Row: 2, ExternalID: "mom", Title: "call mom", IsDone: true}
+	if *records[0] != want {
+		t.Errorf("record = %+v, want %+v", *records[0], want)
+	}
+}
+
+func TestCSVHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		mapping map[string]string
+		message string
+	}{
+		{"mapped column missing", "title,notes\n", map[string]string{"description": "Notes"}, `column "Notes" for description`},
+		{"no title", "name,description\n", nil, "no title column"},
+		{"empty", "", nil, "empty"},
+	}
+	for _, test := range tests {
+		_, err := NewDecoder(FormatCSV, strings.NewReader(test.file), test.mapping)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: err = %v, want it to mention %q", test.name, err, test.message)
+		}
+	}
+}
+
+func TestCSVRowErrors(t *testing.T) {
+	const file = "title,is_favorite\n" +
+		"buy milk,maybe\n" +
+		"call \"mom,false\n"
+
+	dec, err := NewDecoder(FormatCSV, strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rowErrs, err := decodeAll(t, dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 || len(rowErrs) != 2 {
+		t.Fatalf("records = %+v, row errors = %v", records, rowErrs)
+	}
+	if rowErrs[0].Row != 1 || rowErrs[1].Row != 2 {
+		t.Errorf("row errors = %v, want rows 1 and 2", rowErrs)
+	}
+}
+
+func TestCSVQuotesFormulas(t *testing.T) {
+	var b bytes.Buffer
+	enc, err := NewEncoder(FormatCSV, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := &todo.ViewResponse{
+		Model:       gorm.Model{ID: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		Title:       "=HYPERLINK(\"http://evil.example\")",
+		Description: "-1 egg, +2 flour, @home",
+	}
+	if err := enc.Encode(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(b.String(), `"'=HYPERLINK(""http://evil.example"")"`) || !strings.Contains(b.String(), "'-1 egg") {
+		t.Errorf("export does not quote formulas:\n%s", b.String())
+	}
+
+	dec, err := NewDecoder(FormatCSV, &b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Title != data.Title || record.Description != data.Description {
+		t.Errorf("imported %q %q, want %q %q", record.Title, record.Description, data.Title, data.Description)
+	}
+}
@@ -0,0 +1,324 @@
+package codec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// iCalendar (RFC 5545) maps each todo onto a VTODO:
+//
+//   - UID is the external id when there is one, otherwise derived from the
+//     todo id, so it never changes for a todo
+//   - STATUS is COMPLETED or NEEDS-ACTION, with COMPLETED set from
+//     UpdatedAt once done
+//   - favorites get PRIORITY 1; on import PRIORITY 1 to 4 marks a favorite
+//   - SUMMARY and DESCRIPTION are the title and description
+//
+// On import the UID becomes the external id. Todos have no due date, so
+// DUE and the other properties are ignored.
+
+const (
+	icsProductID = "-//todo-crud//todo//EN"
+	icsTime      = "20060102T150405Z"
+	icsDate      = "20060102"
+	icsLineLimit = 75
+)
+
+// ICSUID returns the UID a todo is exported with.
+func ICSUID(data *todo.ViewResponse) string {
+	if data.ExternalID != "" {
+		return data.ExternalID
+	}
+
+	return "todo-" + strconv.FormatUint(uint64(data.ID), 10) + "@todo-crud"
+}
+
+type icsEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+// begin writes the calendar header. It is deferred to the first todo so
+// nothing reaches the writer before the export has produced a row.
+func (c *icsEncoder) begin() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+
+	return c.write(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:"+icsProductID,
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Todos",
+	)
+}
+
+func (c *icsEncoder) Encode(data *todo.ViewResponse) error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + icsEscape(ICSUID(data)),
+		"DTSTAMP:" + data.UpdatedAt.UTC().Format(icsTime),
+		"CREATED:" + data.CreatedAt.UTC().Format(icsTime),
+		"LAST-MODIFIED:" + data.UpdatedAt.UTC().Format(icsTime),
+		"SUMMARY:" + icsEscape(data.Title),
+	}
+
+	if data.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icsEscape(data.Description))
+	}
+
+	if data.IsDone {
+		lines = append(lines,
+			"STATUS:COMPLETED",
+			"COMPLETED:"+data.UpdatedAt.UTC().Format(icsTime),
+			"PERCENT-COMPLETE:100",
+		)
+	} else {
+		lines = append(lines, "STATUS:NEEDS-ACTION")
+	}
+
+	if data.IsFavorite {
+		lines = append(lines, "PRIORITY:1")
+	}
+
+	return c.write(append(lines, "END:VTODO")...)
+}
+
+func (c *icsEncoder) Close() error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+
+	return c.write("END:VCALENDAR")
+}
+
+func (c *icsEncoder) write(lines ...string) error {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icsFold(line))
+	}
+
+	_, err := io.WriteString(c.w, b.String())
+	return err
+}
+
+// icsFold ends line with CRLF, folding it so no line is longer than 75
+// octets without splitting a UTF-8 sequence.
+func icsFold(line string) string {
+	var b strings.Builder
+
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts.
+		limit = icsLineLimit - 1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+// icsEscaper escapes a TEXT value. CRLF, CR and LF line breaks all become
+// \n, since a bare CR would end the content line.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\r", `\n`, "\n", `\n`)
+
+func icsEscape(s string) string {
+	return icsEscaper.Replace(s)
+}
+
+func icsUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+type icsDecoder struct {
+	s       *bufio.Scanner
+	pending string
+	row     int
+	line    int
+}
+
+func newICSDecoder(r io.Reader) *icsDecoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLine)
+
+	return &icsDecoder{s: s}
+}
+
+// next returns the next content line with folding undone.
+func (c *icsDecoder) next() (string, error) {
+	line := c.pending
+	c.pending = ""
+
+	for c.s.Scan() {
+		c.line++
+		text := strings.TrimRight(c.s.Text(), "\r")
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			line += text[1:]
+			continue
+		}
+
+		if line == "" {
+			line = text
+			continue
+		}
+
+		c.pending = text
+		return line, nil
+	}
+
+	if err := c.s.Err(); err != nil {
+		return "", fmt.Errorf("line %d: %s", c.line+1, err)
+	}
+
+	if line == "" {
+		return "", io.EOF
+	}
+
+	return line, nil
+}
+
+// Decode returns the next VTODO. Row counts VTODO components.
+func (c *icsDecoder) Decode() (*Record, error) {
+	var (
+		record *Record
+		status string
+		nested int
+		err    error
+	)
+
+	for {
+		line, nextErr := c.next()
+		if nextErr == io.EOF {
+			if record != nil {
+				return nil, fmt.Errorf("VTODO %d has no END", c.row)
+			}
+			return nil, io.EOF
+		}
+		if nextErr != nil {
+			return nil, nextErr
+		}
+
+		name, value := icsProperty(line)
+		if record == nil {
+			if name == "BEGIN" && strings.EqualFold(value, "VTODO") {
+				c.row++
+				record = &Record{Row: c.row}
+			}
+			continue
+		}
+
+		// Properties of components inside the VTODO, such as VALARM, are
+		// not the todo's.
+		if name == "BEGIN" {
+			nested++
+			continue
+		}
+		if name == "END" && nested > 0 {
+			nested--
+			continue
+		}
+		if nested > 0 {
+			continue
+		}
+
+		switch name {
+		case "END":
+			if err != nil {
+				return nil, &RowError{Row: record.Row, Err: err}
+			}
+			record.IsDone = record.IsDone || status == "COMPLETED"
+			return record, nil
+		case "UID":
+			record.ExternalID = icsUnescape(value)
+		case "SUMMARY":
+			record.Title = icsUnescape(value)
+		case "DESCRIPTION":
+			record.Description = icsUnescape(value)
+		case "STATUS":
+			status = strings.ToUpper(value)
+		case "COMPLETED":
+			record.IsDone = true
+		case "PRIORITY":
+			priority, parseErr := strconv.Atoi(strings.TrimSpace(value))
+			if parseErr != nil && err == nil {
+				err = fmt.Errorf("invalid PRIORITY %q", value)
+			}
+			record.IsFavorite = priority >= 1 && priority <= 4
+		case "CREATED":
+			created, parseErr := parseICSTime(value)
+			if parseErr != nil && err == nil {
+				err = fmt.Errorf("invalid CREATED %q", value)
+			}
+			record.CreatedAt = created
+		}
+	}
+}
+
+// icsProperty splits a content line into its upper-cased name and value,
+// dropping any parameters.
+func icsProperty(line string) (string, string) {
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ':' && !quoted:
+			name := line[:i]
+			if j := strings.Index(name, ";"); j >= 0 {
+				name = name[:j]
+			}
+			return strings.ToUpper(name), line[i+1:]
+		}
+	}
+
+	return strings.ToUpper(line), ""
+}
+
+func parseICSTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{icsTime, "20060102T150405", icsDate} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
@@ -0,0 +1,162 @@
+package codec
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/jinzhu/gorm"
+)
+
+func TestICSEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"milk, eggs; bread", `milk\, eggs\; bread`},
+		{`C:\todo`, `C:\\todo`},
+		{"one\ntwo", `one\ntwo`},
+		{"one\r\ntwo", `one\ntwo`},
+		{"one\rtwo", `one\ntwo`},
+		{"one\r\n\rtwo", `one\n\ntwo`},
+	}
+	for _, test := range tests {
+		got := icsEscape(test.text)
+		if got != test.want {
+			t.Errorf("icsEscape(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestICSDecode(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//Tasks//EN",
+		"BEGIN:VTODO",
+		"UID;X-SOURCE=phone:8D3C7E0A-2B64-4C61-",
+		" 9E0B-5F1C2A7D9B11",
+		"SUMMARY:Call the plu",
+		" mber",
+		"DESCRIPTION:About the kitchen sink\\, again\\nand the",
+		"\t bathroom",
+		"STATUS:COMPLETED",
+		"PRIORITY:3",
+		"CREATED:20200301T090000Z",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:todo-7@todo-crud",
+		"SUMMARY:Buy milk",
+		"STATUS:NEEDS-ACTION",
+		"PRIORITY:5",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	records, rowErrs, err := decodeAll(t, newICSDecoder(strings.NewReader(input)))
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("decode: %v %v", err, rowErrs)
+	}
+
+	want := []*Record{
+		{
+			Row:         1,
+			ExternalID:  "8D3C7E0A-2B64-4C61-9E0B-5F1C2A7D9B11",
+			Title:       "Call the plumber",
+			Description: "About the kitchen sink, again\nand the bathroom",
+			IsDone:      true,
+			IsFavorite:  true,
+			CreatedAt:   time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{Row: 2, ExternalID: "todo-7@todo-crud", Title: "Buy milk"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		for i := range records {
+			t.Logf("got %+v", records[i])
+		}
+		t.Fatalf("records differ from %d expected", len(want))
+	}
+}
+
+func TestICSRoundTrip(t *testing.T) {
+	created := time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC)
+	todos := []*todo.ViewResponse{
+		{
+			Model:       gorm.Model{ID: 1, CreatedAt: created, UpdatedAt: created.Add(time.Hour)},
+			Title:       "Call the plumber",
+			Description: "About the kitchen sink; " + strings.Repeat("the tap drips, ", 6) + "again\nThanks ☕",
+			IsFavorite:  true,
+			ExternalID:  "8D3C7E0A-2B64-4C61-9E0B-5F1C2A7D9B11",
+		},
+		{
+			Model:       gorm.Model{ID: 2, CreatedAt: created, UpdatedAt: created.Add(2 * time.Hour)},
+			Title:       "Buy milk",
+			Description: "Two liters, semi-skimmed",
+			IsDone:      true,
+		},
+	}
+
+	first := encodeICS(t, todos)
+	for _, line := range strings.Split(string(first), "\r\n") {
+		if len(line) > icsLineLimit {
+			t.Errorf("line longer than %d octets: %q", icsLineLimit, line)
+		}
+	}
+
+	records, rowErrs, err := decodeAll(t, newICSDecoder(bytes.NewReader(first)))
+	if err != nil || len(rowErrs) > 0 {
+		t.Fatalf("decode: %v %v", err, rowErrs)
+	}
+	if len(records) != len(todos) {
+		t.Fatalf("decoded %d todos, want %d", len(records), len(todos))
+	}
+
+	// Imported into another server, the todos get new ids but keep their
+	// UID as the external id, so exporting them again keeps the UIDs.
+	imported := make([]*todo.ViewResponse, 0, len(records))
+	for i, record := range records {
+		data := todos[i]
+		if record.ExternalID != ICSUID(data) || record.Title != data.Title || record.Description != data.Description ||
+			record.IsDone != data.IsDone || record.IsFavorite != data.IsFavorite || !record.CreatedAt.Equal(data.CreatedAt) {
+			t.Errorf("todo %d: decoded %+v", data.ID, record)
+		}
+
+		imported = append(imported, &todo.ViewResponse{
+			Model:       gorm.Model{ID: uint(100 + i), CreatedAt: record.CreatedAt, UpdatedAt: data.UpdatedAt},
+			Title:       record.Title,
+			Description: record.Description,
+			IsDone:      record.IsDone,
+			IsFavorite:  record.IsFavorite,
+			ExternalID:  record.ExternalID,
+		})
+	}
+
+	if second := encodeICS(t, imported); !bytes.Equal(first, second) {
+		t.Errorf("export after import differs:\n%s\nwant:\n%s", second, first)
+	}
+}
+
+func encodeICS(t *testing.T, todos []*todo.ViewResponse) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	enc := &icsEncoder{w: &b}
+	for _, data := range todos {
+		if err := enc.Encode(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Bytes()
+}
@@ -0,0 +1,158 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// maxLine bounds a single NDJSON line.
+const maxLine = 1 << 20
+
+// jsonRecord is the subset of a todo read on import. Exported files carry
+// more fields, which are ignored.
+type jsonRecord struct {
+	ExternalID  string `json:"external_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	IsDone      bool   `json:"is_done"`
+	IsFavorite  bool   `json:"is_favorite"`
+}
+
+func (r *jsonRecord) record(row int) *Record {
+	return &Record{
+		Row:         row,
+		ExternalID:  r.ExternalID,
+		Title:       r.Title,
+		Description: r.Description,
+		IsDone:      r.IsDone,
+		IsFavorite:  r.IsFavorite,
+	}
+}
+
+// jsonEncoder writes a single JSON array, one element at a time.
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (c *jsonEncoder) Encode(data *todo.ViewResponse) error {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n"
+	if c.count == 0 {
+		prefix = "[\n"
+	}
+	c.count++
+
+	_, err = c.w.Write(append([]byte(prefix), content...))
+	return err
+}
+
+func (c *jsonEncoder) Close() error {
+	end := "\n]\n"
+	if c.count == 0 {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(c.w, end)
+	return err
+}
+
+type ndjsonEncoder struct {
+	w io.Writer
+}
+
+func (c *ndjsonEncoder) Encode(data *todo.ViewResponse) error {
+	return json.NewEncoder(c.w).Encode(data)
+}
+
+func (c *ndjsonEncoder) Close() error {
+	return nil
+}
+
+// jsonDecoder reads the elements of a JSON array one at a time, so a
+// large file is never held in memory.
+type jsonDecoder struct {
+	d   *json.Decoder
+	row int
+}
+
+func newJSONDecoder(r io.Reader) (*jsonDecoder, error) {
+	d := json.NewDecoder(r)
+
+	token, err := d.Token()
+	if err != nil {
+		return nil, errors.New("invalid json body")
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("json body should be an array")
+	}
+
+	return &jsonDecoder{d: d}, nil
+}
+
+func (c *jsonDecoder) Decode() (*Record, error) {
+	if !c.d.More() {
+		if _, err := c.d.Token(); err != nil {
+			return nil, fmt.Errorf("invalid json after row %d", c.row)
+		}
+		return nil, io.EOF
+	}
+
+	c.row++
+	data := new(jsonRecord)
+	if err := c.d.Decode(data); err != nil {
+		// A type error leaves the decoder after the element, so the next
+		// row can still be read. Syntax errors cannot be recovered.
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			return nil, &RowError{Row: c.row, Err: fmt.Errorf("%s should be %s", typeErr.Field, typeErr.Type)}
+		}
+		return nil, fmt.Errorf("invalid json at row %d", c.row)
+	}
+
+	return data.record(c.row), nil
+}
+
+type ndjsonDecoder struct {
+	s   *bufio.Scanner
+	row int
+}
+
+func newNDJSONDecoder(r io.Reader) *ndjsonDecoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLine)
+
+	return &ndjsonDecoder{s: s}
+}
+
+func (c *ndjsonDecoder) Decode() (*Record, error) {
+	for c.s.Scan() {
+		line := bytes.TrimSpace(c.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		c.row++
+		data := new(jsonRecord)
+		if err := json.Unmarshal(line, data); err != nil {
+			return nil, &RowError{Row: c.row, Err: errors.New("invalid json")}
+		}
+
+		return data.record(c.row), nil
+	}
+
+	if err := c.s.Err(); err != nil {
+		return nil, fmt.Errorf("row %d: %s", c.row+1, err)
+	}
+
+	return nil, io.EOF
+}
@@ -0,0 +1,90 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONTypeErrorSkipsTheRow(t *testing.T) {
+	const file = `[
+		{"title": "buy milk", "is_done": "yes"},
+		{"title": "call mom", "external_id": "mom", "is_favorite": true},
+		{"title": 3}
+	]`
+
+	dec, err := NewDecoder(FormatJSON, strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rowErrs, err := decodeAll(t, dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rowErrs) != 2 || rowErrs[0].Row != 1 || rowErrs[1].Row != 3 {
+		t.Errorf("row errors = %v, want rows 1 and 3", rowErrs)
+	}
+	if !strings.Contains(rowErrs[0].Error(), "is_done should be bool") {
+		t.Errorf("row 1: %v", rowErrs[0])
+	}
+	if len(records) != 1 || *records[0] != (Record{Row: 2, ExternalID: "mom", Title: "call mom", IsFavorite: true}) {
+		t.Errorf("records = %+v", records)
+	}
+}
+
+func TestJSONSyntaxErrorEndsDecoding(t *testing.T) {
+	dec, err := NewDecoder(FormatJSON, strings.NewReader(`[{"title": "buy milk"}, {"title": }]`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, _, err := decodeAll(t, dec)
+	if err == nil || len(records) != 1 {
+		t.Errorf("records = %+v, err = %v; want one record, then an error", records, err)
+	}
+
+	if _, err := NewDecoder(FormatJSON, strings.NewReader(`{"title": "buy milk"}`), nil); err == nil {
+		t.Error("an object was accepted as the body")
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	file := `{"title": "buy milk"}` + "\n\n" +
+		"not json\n" +
+		`{"title": "call mom", "is_done": true}` + "\n"
+
+	dec, err := NewDecoder(FormatNDJSON, strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rowErrs, err := decodeAll(t, dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rowErrs) != 1 || rowErrs[0].Row != 2 {
+		t.Errorf("row errors = %v, want row 2", rowErrs)
+	}
+	if len(records) != 2 || records[1].Row != 3 || !records[1].IsDone {
+		t.Errorf("records = %+v", records)
+	}
+}
+
+func TestNDJSONLineTooLong(t *testing.T) {
+	file := `{"title": "buy milk"}` + "\n" +
+		`{"title": "` + strings.Repeat("x", maxLine) + `"}` + "\n" +
+		`{"title": "call mom"}` + "\n"
+
+	dec, err := NewDecoder(FormatNDJSON, strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, _, err := decodeAll(t, dec)
+	if len(records) != 1 {
+		t.Errorf("records = %+v, want the one before the long line", records)
+	}
+	if _, ok := err.(*RowError); err == nil || ok || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("err = %v, want decoding to end at row 2", err)
+	}
+}
@@ -0,0 +1,146 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// A Markdown checklist item is written as
+//
+//	- [x] title <!-- todo:42 -->
+//	  description, indented below the item
+//
+// The comment carries the todo id so a later sync can match the item. Any
+// lines indented deeper than the item, up to a blank line or the next
+// item, are its description. Todos have no subtasks, so nested items are
+// read as todos of their own and everything is written flat.
+
+var (
+	checklistItem    = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\] (.*)$`)
+	checklistComment = regexp.MustCompile(`\s*<!--\s*todo:([0-9]+)\s*-->\s*$`)
+)
+
+// ChecklistItem is one item of a Markdown checklist. Line is the 0-based
+// index of its line in the document.
+type ChecklistItem struct {
+	Line        int
+	ID          int
+	Done        bool
+	Title       string
+	Description string
+}
+
+// ParseChecklist returns the checklist items of a Markdown document in
+// order. Lines that are not items are skipped.
+func ParseChecklist(lines []string) []ChecklistItem {
+	items := make([]ChecklistItem, 0)
+
+	for i := 0; i < len(lines); i++ {
+		match := checklistItem.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if match == nil {
+			continue
+		}
+
+		item := ChecklistItem{
+			Line:  i,
+			Done:  match[2] != " ",
+			Title: match[3],
+		}
+
+		if comment := checklistComment.FindStringSubmatchIndex(item.Title); comment != nil {
+			item.ID, _ = strconv.Atoi(item.Title[comment[2]:comment[3]])
+			item.Title = item.Title[:comment[0]]
+		}
+		item.Title = strings.TrimSpace(item.Title)
+
+		indent := len(match[1])
+		description := make([]string, 0)
+		for j := i + 1; j < len(lines); j++ {
+			line := strings.TrimRight(lines[j], "\r")
+			trimmed := strings.TrimLeft(line, " \t")
+			if trimmed == "" || len(line)-len(trimmed) <= indent || checklistItem.MatchString(line) {
+				break
+			}
+			description = append(description, strings.TrimSpace(trimmed))
+		}
+		item.Description = strings.Join(description, "\n")
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// ChecklistComment returns the comment that ties an item to a todo.
+func ChecklistComment(id uint) string {
+	return fmt.Sprintf("<!-- todo:%d -->", id)
+}
+
+type markdownEncoder struct {
+	w io.Writer
+}
+
+func (c *markdownEncoder) Encode(data *todo.ViewResponse) error {
+	check := " "
+	if data.IsDone {
+		check = "x"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "- [%s] %s %s\n", check, strings.Join(strings.Fields(data.Title), " "), ChecklistComment(data.ID))
+	for _, line := range strings.Split(data.Description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	_, err := io.WriteString(c.w, b.String())
+	return err
+}
+
+func (c *markdownEncoder) Close() error {
+	return nil
+}
+
+// markdownDecoder imports checklist items as new todos; the id comments
+// are ignored, since ids are not portable between servers. Rows are line
+// numbers.
+type markdownDecoder struct {
+	items []ChecklistItem
+}
+
+func newMarkdownDecoder(r io.Reader) (*markdownDecoder, error) {
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxLine*16))
+	if err != nil {
+		return nil, err
+	}
+
+	return &markdownDecoder{items: ParseChecklist(strings.Split(string(content), "\n"))}, nil
+}
+
+func (c *markdownDecoder) Decode() (*Record, error) {
+	if len(c.items) == 0 {
+		return nil, io.EOF
+	}
+
+	item := c.items[0]
+	c.items = c.items[1:]
+
+	description := item.Description
+	if description == "" {
+		description = item.Title
+	}
+
+	return &Record{
+		Row:         item.Line + 1,
+		Title:       item.Title,
+		Description: description,
+		IsDone:      item.Done,
+	}, nil
+}
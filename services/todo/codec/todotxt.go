@@ -0,0 +1,241 @@
+package codec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// The todo.txt format (https://github.com/todotxt/todo.txt) has one task
+// per line:
+//
+//	(A) 2020-03-01 call mom +family @phone due:2020-03-05
+//	x 2020-03-02 2020-03-01 call mom +family @phone due:2020-03-05 pri:A
+//
+// It maps onto a todo as follows:
+//
+//   - a leading "x" marks the todo done; the completion date is written
+//     from UpdatedAt and ignored on import
+//   - any priority, or a pri: extra, marks the todo favorite; favorites are
+//     written as (A), or as pri:A once done since done tasks drop priority
+//   - the creation date is CreatedAt
+//   - the id: extra is the external id, so re-importing a file updates the
+//     todos it came from
+//   - the text is the description, with +project, @context and other
+//     key:value extras left in place; the title is the text without them
+//
+// Todos have no lists or tags, so projects and contexts only live in the
+// description text.
+
+const (
+	todoTxtDate     = "2006-01-02"
+	maxTitleLength  = 100
+	todoTxtPriority = "A"
+)
+
+var todoTxtPriorityPattern = regexp.MustCompile(`^\([A-Z]\)$`)
+
+type todoTxtEncoder struct {
+	w io.Writer
+}
+
+func (c *todoTxtEncoder) Encode(data *todo.ViewResponse) error {
+	parts := make([]string, 0, 6)
+	if data.IsDone {
+		parts = append(parts, "x", data.UpdatedAt.Format(todoTxtDate))
+	} else if data.IsFavorite {
+		parts = append(parts, "("+todoTxtPriority+")")
+	}
+
+	if !data.CreatedAt.IsZero() {
+		parts = append(parts, data.CreatedAt.Format(todoTxtDate))
+	}
+
+	parts = append(parts, todoTxtText(data))
+
+	if data.IsDone && data.IsFavorite {
+		parts = append(parts, "pri:"+todoTxtPriority)
+	}
+	if data.ExternalID != "" && !strings.ContainsAny(data.ExternalID, " \t") {
+		parts = append(parts, "id:"+data.ExternalID)
+	}
+
+	_, err := io.WriteString(c.w, strings.Join(parts, " ")+"\n")
+	return err
+}
+
+func (c *todoTxtEncoder) Close() error {
+	return nil
+}
+
+// todoTxtText returns the task text of a todo. A todo imported from
+// todo.txt has the text as its description; any other todo is written as
+// its title followed by its description on the same line.
+func todoTxtText(data *todo.ViewResponse) string {
+	description := strings.Join(strings.Fields(data.Description), " ")
+	if todoTxtTitle(description) == data.Title {
+		return description
+	}
+
+	title := strings.Join(strings.Fields(data.Title), " ")
+	if description == "" {
+		return title
+	}
+
+	return title + " " + description
+}
+
+type todoTxtDecoder struct {
+	s   *bufio.Scanner
+	row int
+}
+
+func newTodoTxtDecoder(r io.Reader) *todoTxtDecoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLine)
+
+	return &todoTxtDecoder{s: s}
+}
+
+// Decode returns the task on the next non-blank line. Row is the line
+// number.
+func (c *todoTxtDecoder) Decode() (*Record, error) {
+	for c.s.Scan() {
+		c.row++
+		line := strings.TrimSpace(c.s.Text())
+		if line == "" {
+			continue
+		}
+
+		record, err := parseTodoTxt(line)
+		if err != nil {
+			return nil, &RowError{Row: c.row, Err: err}
+		}
+		record.Row = c.row
+
+		return record, nil
+	}
+
+	if err := c.s.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %s", c.row+1, err)
+	}
+
+	return nil, io.EOF
+}
+
+func parseTodoTxt(line string) (*Record, error) {
+	record := new(Record)
+	fields := strings.Fields(line)
+
+	if len(fields) > 0 && fields[0] == "x" {
+		record.IsDone = true
+		fields = fields[1:]
+
+		// Completion date, then creation date.
+		if len(fields) > 0 && isTodoTxtDate(fields[0]) {
+			fields = fields[1:]
+		}
+	} else if len(fields) > 0 && todoTxtPriorityPattern.MatchString(fields[0]) {
+		record.IsFavorite = true
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && isTodoTxtDate(fields[0]) {
+		record.CreatedAt, _ = time.ParseInLocation(todoTxtDate, fields[0], time.UTC)
+		fields = fields[1:]
+	}
+
+	text := make([]string, 0, len(fields))
+	for _, field := range fields {
+		key, value, ok := todoTxtExtra(field)
+		switch {
+		case ok && key == "id":
+			record.ExternalID = value
+		case ok && key == "pri":
+			record.IsFavorite = true
+		default:
+			text = append(text, field)
+		}
+	}
+
+	if len(text) == 0 {
+		return nil, fmt.Errorf("task has no text")
+	}
+
+	record.Description = strings.Join(text, " ")
+	record.Title = todoTxtTitle(record.Description)
+
+	return record, nil
+}
+
+// todoTxtTitle strips +project, @context and key:value extras from text
+// and cuts the rest to the longest title a todo accepts. It falls back to
+// text when nothing is left.
+func todoTxtTitle(text string) string {
+	words := make([]string, 0)
+	for _, field := range strings.Fields(text) {
+		if isTodoTxtTag(field) {
+			continue
+		}
+		if _, _, ok := todoTxtExtra(field); ok {
+			continue
+		}
+		words = append(words, field)
+	}
+
+	title := strings.Join(words, " ")
+	if title == "" {
+		title = text
+	}
+
+	if utf8.RuneCountInString(title) <= maxTitleLength {
+		return title
+	}
+
+	return string([]rune(title)[:maxTitleLength])
+}
+
+func isTodoTxtTag(field string) bool {
+	return len(field) > 1 && (field[0] == '+' || field[0] == '@')
+}
+
+// todoTxtExtra splits a key:value extra. URLs such as http://example.com
+// and times such as 10:30 are not extras.
+func todoTxtExtra(field string) (string, string, bool) {
+	i := strings.Index(field, ":")
+	if i <= 0 || i == len(field)-1 {
+		return "", "", false
+	}
+
+	key, value := field[:i], field[i+1:]
+	if strings.HasPrefix(value, "/") || isDigits(key) {
+		return "", "", false
+	}
+
+	return key, value, true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isTodoTxtDate(field string) bool {
+	if len(field) != len(todoTxtDate) {
+		return false
+	}
+
+	_, err := time.Parse(todoTxtDate, field)
+	return err == nil
+}
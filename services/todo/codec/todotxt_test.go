@@ -0,0 +1,129 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/jinzhu/gorm"
+)
+
+func decodeTodoTxt(t *testing.T, r io.Reader) []*Record {
+	t.Helper()
+
+	dec := newTodoTxtDecoder(r)
+	records := make([]*Record, 0)
+	for {
+		record, err := dec.Decode()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+// formatTodoTxt writes records the way an export would, after they were
+// imported as new todos.
+func formatTodoTxt(t *testing.T, records []*Record) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	enc := &todoTxtEncoder{w: &b}
+	for i, record := range records {
+		data := &todo.ViewResponse{
+			Model:       gorm.Model{ID: uint(i + 1), CreatedAt: record.CreatedAt, UpdatedAt: time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC)},
+			Title:       record.Title,
+			Description: record.Description,
+			IsDone:      record.IsDone,
+			IsFavorite:  record.IsFavorite,
+			ExternalID:  record.ExternalID,
+		}
+		if err := enc.Encode(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Bytes()
+}
+
+// TestTodoTxtRoundTrip parses each file in testdata, formats the todos and
+// parses the result again; both parses must give the same todos, and
+// formatting those again must give the same file.
+func TestTodoTxtRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no todo.txt files in testdata")
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed := decodeTodoTxt(t, f)
+		f.Close()
+
+		formatted := formatTodoTxt(t, parsed)
+		reparsed := decodeTodoTxt(t, bytes.NewReader(formatted))
+		if len(reparsed) != len(parsed) {
+			t.Fatalf("%s: %d todos after the round trip, want %d:\n%s", file, len(reparsed), len(parsed), formatted)
+		}
+		for i := range parsed {
+			// Rows differ where the file had blank lines.
+			parsed[i].Row, reparsed[i].Row = 0, 0
+			if !reflect.DeepEqual(reparsed[i], parsed[i]) {
+				t.Errorf("%s: todo %d = %+v, want %+v", file, i+1, reparsed[i], parsed[i])
+			}
+		}
+
+		if again := formatTodoTxt(t, reparsed); !bytes.Equal(again, formatted) {
+			t.Errorf("%s: formatting is not stable:\n%s\nthen:\n%s", file, formatted, again)
+		}
+	}
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	tests := []struct {
+		line string
+		want Record
+	}{
+		{"(A) call mom", Record{Title: "call mom", Description: "call mom", IsFavorite: true}},
+		{
+			"x 2020-03-02 2020-03-01 call mom +family due:2020-03-05 pri:A id:mom",
+			Record{
+				ExternalID:  "mom",
+				Title:       "call mom",
+				Description: "call mom +family due:2020-03-05",
+				IsDone:      true,
+				IsFavorite:  true,
+				CreatedAt:   time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{"dentist at 10:30", Record{Title: "dentist at 10:30", Description: "dentist at 10:30"}},
+		{"read http://example.com", Record{Title: "read http://example.com", Description: "read http://example.com"}},
+	}
+	for _, test := range tests {
+		got, err := parseTodoTxt(test.line)
+		if err != nil {
+			t.Errorf("%q: %s", test.line, err)
+			continue
+		}
+		if *got != test.want {
+			t.Errorf("%q = %+v, want %+v", test.line, *got, test.want)
+		}
+	}
+}
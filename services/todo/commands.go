@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/services/todo/backup"
+	"github.com/ardiantirta/todo-crud/services/todo/config"
+	"github.com/ardiantirta/todo-crud/services/todo/migration"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+)
+
+const commandUsage = `usage: todo-server [flags] [command]
+
+Without a command the server starts. Every setting can come from the
+config file, a TODO_* environment variable or a flag; run todo-server -h
+to list them.
+
+commands:
+  backup [--gzip] <file>   write every todo, soft-deleted ones included,
+                           to an archive; a .gz file is always compressed
+  restore <file>           restore an archive into an empty database
+  migrate up               apply every pending migration
+  migrate down             revert the latest migration
+  migrate to <version>     migrate up or down to a version
+  migrate status           list migrations and when they were applied
+  config print             print the effective configuration, secrets
+                           redacted, and whether it is valid
+`
+
+// runCommand runs a maintenance command instead of the server. Commands
+// other than migrate need the schema this build expects.
+func runCommand(migrator *migration.Migrator, repo repository.Repository, args []string) error {
+	if args[0] == "migrate" {
+		return runMigrate(migrator, args[1:])
+	}
+
+	if args[0] == "backup" || args[0] == "restore" {
+		if err := migrator.Check(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	switch args[0] {
+	case "backup":
+		return runBackup(repo, args[1:])
+	case "restore":
+		return runRestore(repo, args[1:])
+	}
+
+	fmt.Fprint(os.Stderr, commandUsage)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// runConfig runs the config commands, which need no database.
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Fprint(os.Stderr, commandUsage)
+		return fmt.Errorf("config needs print")
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+
+	return cfg.Validate()
+}
+
+func runBackup(repo repository.Repository, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, commandUsage) }
+	compress := fs.Bool("gzip", false, "gzip the archive")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("backup needs a file")
+	}
+	name := fs.Arg(0)
+
+	// The archive is written next to its destination and renamed, so a
+	// failed backup does not replace a good one.
+	f, err := os.Create(name + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	summary, err := backup.Write(context.Background(), repo, f, *compress || strings.HasSuffix(name, ".gz"))
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.Name(), name); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "wrote %d todos to %s (%s)\n", summary.Rows, name, summary.Checksum)
+	return nil
+}
+
+func runRestore(repo repository.Repository, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, commandUsage) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("restore needs a file")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	summary, err := backup.Read(context.Background(), repo, f)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "restored %d todos from a backup of %s\n", summary.Rows, summary.Header.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	return nil
+}
+
+func runMigrate(migrator *migration.Migrator, args []string) error {
+	ctx := context.Background()
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, commandUsage)
+		return fmt.Errorf("migrate needs up, down, to or status")
+	}
+
+	before, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	applied := make(map[int]bool)
+	for _, s := range before {
+		applied[s.Version] = s.AppliedAt != nil
+	}
+
+	var ran []migration.Migration
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		ran, err = migrator.Up(ctx)
+	case args[0] == "down" && len(args) == 1:
+		ran, err = migrator.Down(ctx)
+	case args[0] == "to" && len(args) == 2:
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 {
+			return fmt.Errorf("version should be a number")
+		}
+		ran, err = migrator.To(ctx, version)
+	case args[0] == "status" && len(args) == 1:
+		return printMigrationStatus(migrator)
+	default:
+		fmt.Fprint(os.Stderr, commandUsage)
+		return fmt.Errorf("unknown migrate command %q", strings.Join(args, " "))
+	}
+
+	for _, m := range ran {
+		if applied[m.Version] {
+			fmt.Fprintf(os.Stderr, "reverted %d %s\n", m.Version, m.Name)
+		} else {
+			fmt.Fprintf(os.Stderr, "applied %d %s\n", m.Version, m.Name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := migrator.Current(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "schema is at version %d\n", current)
+	return nil
+}
+
+func printMigrationStatus(migrator *migration.Migrator) error {
+	status, err := migrator.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, s := range status {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Printf("%4d  %-30s  %s\n", s.Version, s.Name, applied)
+	}
+
+	return nil
+}
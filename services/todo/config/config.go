@@ -0,0 +1,479 @@
+// Package config loads the server configuration. Each layer overrides the
+// one before it: defaults, the JSON config file, TODO_* environment
+// variables and command line flags.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ardiantirta/todo-crud/common/cors"
+	"github.com/ardiantirta/todo-crud/common/logging"
+	"github.com/ardiantirta/todo-crud/common/tracing"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// DefaultFile is read when neither -config nor TODO_CONFIG names a file.
+// Unlike a named file, it may be missing.
+const DefaultFile = "./config/config.json"
+
+const (
+	envPrefix = "TODO"
+	redacted  = "REDACTED"
+)
+
+// defaults lists every key the server reads. Only the keys listed here
+// can be set from the environment or flags.
+var defaults = map[string]interface{}{
+	"debug":                            false,
+	"server.address":                   ":9091",
+	"server.read_header_timeout":       "5s",
+	"server.read_timeout":              "30s",
+	"server.write_timeout":             "0s",
+	"server.idle_timeout":              "120s",
+	"server.max_header_bytes":          http.DefaultMaxHeaderBytes,
+	"server.max_body_bytes":            10 << 20,
+	"server.max_import_bytes":          100 << 20,
+	"server.drain_delay":               "0s",
+	"server.shutdown_timeout":          "30s",
+	"grpc.address":                     "",
+	"database.host":                    "",
+	"database.port":                    "5432",
+	"database.user":                    "",
+	"database.pass":                    "",
+	"database.pass_file":               "",
+	"database.name":                    "",
+	"database.timeout":                 "5s",
+	"sync.conflict":                    todo.ConflictLastWriterWins,
+	"calendar.token":                   "",
+	"calendar.token_file":              "",
+	"metrics.address":                  "",
+	"tracing.exporter":                 tracing.ExporterNone,
+	"tracing.endpoint":                 "",
+	"tracing.sample_ratio":             1,
+	"health.timeout":                   "1s",
+	"health.cache":                     "2s",
+	"cors.allowed_origins":             []string{},
+	"cors.allowed_methods":             []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
+	"cors.allowed_headers":             []string{"Content-Type", "X-Requested-With", "Authorization", logging.Header, "traceparent", "tracestate"},
+	"cors.exposed_headers":             []string{logging.Header},
+	"cors.allow_credentials":           false,
+	"cors.max_age":                     "10m",
+	"security.hsts_max_age":            "4320h",
+	"security.hsts_include_subdomains": false,
+	"security.frame_options":           "DENY",
+	"security.content_security_policy": "default-src 'self'; object-src 'none'; base-uri 'self'; form-action 'self'; frame-ancestors 'none'",
+	"security.referrer_policy":         "same-origin",
+}
+
+// operationTimeouts and cors.routes are not plain values, so they only
+// come from the file.
+var operationTimeouts = map[string]interface{}{"each": "1m"}
+
+// secrets are redacted by Print. Each can instead be read from the file
+// named by its _file key, as with TODO_DATABASE_PASS_FILE, so it never
+// shows up in the environment or the process list.
+var secrets = []string{"database.pass", "calendar.token"}
+
+type Config struct {
+	Debug    bool     `mapstructure:"debug"`
+	Server   Server   `mapstructure:"server"`
+	GRPC     GRPC     `mapstructure:"grpc"`
+	Database Database `mapstructure:"database"`
+	Sync     Sync     `mapstructure:"sync"`
+	Calendar Calendar `mapstructure:"calendar"`
+	Metrics  Metrics  `mapstructure:"metrics"`
+	Tracing  Tracing  `mapstructure:"tracing"`
+	Health   Health   `mapstructure:"health"`
+	CORS     CORS     `mapstructure:"cors"`
+	Security Security `mapstructure:"security"`
+
+	// File is the config file read, empty when there was none.
+	File string `mapstructure:"-"`
+
+	settings map[string]interface{}
+}
+
+type Server struct {
+	Address           string        `mapstructure:"address"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	// WriteTimeout bounds the whole response, so it is off by default for
+	// the sake of server-sent events.
+	WriteTimeout   time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout    time.Duration `mapstructure:"idle_timeout"`
+	MaxHeaderBytes int           `mapstructure:"max_header_bytes"`
+	MaxBodyBytes   int64         `mapstructure:"max_body_bytes"`
+	// MaxImportBytes replaces MaxBodyBytes for imports, whose files are
+	// much larger than any other request.
+	MaxImportBytes int64 `mapstructure:"max_import_bytes"`
+	// DrainDelay is how long readiness fails before the server stops
+	// accepting connections, so load balancers notice first.
+	DrainDelay      time.Duration `mapstructure:"drain_delay"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+type GRPC struct {
+	// Address is empty when the gRPC server is off.
+	Address string `mapstructure:"address"`
+}
+
+type Database struct {
+	Host string `mapstructure:"host"`
+	Port string `mapstructure:"port"`
+	User string `mapstructure:"user"`
+	Pass string `mapstructure:"pass"`
+	Name string `mapstructure:"name"`
+	// Timeout bounds each repository call. OperationTimeouts overrides it
+	// per repository method, by lower-case method name. The streaming
+	// methods each, dump and restore only count the time spent waiting on
+	// the database, so a slow export client is not cut off.
+	Timeout           time.Duration            `mapstructure:"timeout"`
+	OperationTimeouts map[string]time.Duration `mapstructure:"operation_timeouts"`
+}
+
+type Sync struct {
+	Conflict string `mapstructure:"conflict"`
+}
+
+type Calendar struct {
+	// Token protects the calendar feed. Empty leaves it open.
+	Token string `mapstructure:"token"`
+}
+
+type Metrics struct {
+	// Address is empty when metrics are served on the main server.
+	Address string `mapstructure:"address"`
+}
+
+type Tracing struct {
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type Health struct {
+	Timeout time.Duration `mapstructure:"timeout"`
+	Cache   time.Duration `mapstructure:"cache"`
+}
+
+// CORS is the default cross-origin policy. No allowed origins means no
+// cross-origin access.
+type CORS struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+	Routes           []CORSRoute   `mapstructure:"routes"`
+}
+
+// CORSRoute overrides the default policy for Prefix and the paths under
+// it. Settings it leaves out are inherited; an empty list clears
+// the inherited one.
+type CORSRoute struct {
+	Prefix           string         `mapstructure:"prefix"`
+	AllowedOrigins   *[]string      `mapstructure:"allowed_origins"`
+	AllowedMethods   *[]string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   *[]string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   *[]string      `mapstructure:"exposed_headers"`
+	AllowCredentials *bool          `mapstructure:"allow_credentials"`
+	MaxAge           *time.Duration `mapstructure:"max_age"`
+}
+
+// Policies returns the default policy and the policy of every route
+// prefix, with the inherited settings filled in.
+func (c CORS) Policies() (cors.Policy, map[string]cors.Policy) {
+	fallback := cors.Policy{
+		AllowedOrigins:   trim(c.AllowedOrigins),
+		AllowedMethods:   trim(c.AllowedMethods),
+		AllowedHeaders:   trim(c.AllowedHeaders),
+		ExposedHeaders:   trim(c.ExposedHeaders),
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+
+	routes := make(map[string]cors.Policy, len(c.Routes))
+	for _, route := range c.Routes {
+		policy := fallback
+		if route.AllowedOrigins != nil {
+			policy.AllowedOrigins = trim(*route.AllowedOrigins)
+		}
+		if route.AllowedMethods != nil {
+			policy.AllowedMethods = trim(*route.AllowedMethods)
+		}
+		if route.AllowedHeaders != nil {
+			policy.AllowedHeaders = trim(*route.AllowedHeaders)
+		}
+		if route.ExposedHeaders != nil {
+			policy.ExposedHeaders = trim(*route.ExposedHeaders)
+		}
+		if route.AllowCredentials != nil {
+			policy.AllowCredentials = *route.AllowCredentials
+		}
+		if route.MaxAge != nil {
+			policy.MaxAge = *route.MaxAge
+		}
+		routes[route.Prefix] = policy
+	}
+
+	return fallback, routes
+}
+
+type Security struct {
+	// HSTSMaxAge is only sent on requests that came over HTTPS.
+	HSTSMaxAge            time.Duration `mapstructure:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `mapstructure:"hsts_include_subdomains"`
+	FrameOptions          string        `mapstructure:"frame_options"`
+	// ContentSecurityPolicy goes on HTML responses. The web UI only loads
+	// its own scripts and styles, so the default allows nothing else.
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`
+}
+
+// Load reads the configuration with args as the command line, returning
+// the arguments left after the flags. It does not validate the result;
+// call Validate for that.
+func Load(args []string) (*Config, []string, error) {
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	v.SetDefault("database.operation_timeouts", operationTimeouts)
+
+	fs := flag.NewFlagSet("todo-server", flag.ContinueOnError)
+	file := fs.String("config", "", "config file (default "+DefaultFile+", env "+envPrefix+"_CONFIG)")
+	keys := sortedKeys()
+	for _, key := range keys {
+		fs.String(key, "", "overrides "+key+" (env "+envName(key)+")")
+	}
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "usage: todo-server [flags] [command]\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	path := *file
+	if path == "" {
+		path = os.Getenv(envPrefix + "_CONFIG")
+	}
+	if path == "" {
+		if _, err := os.Stat(DefaultFile); err == nil {
+			path = DefaultFile
+		}
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, nil, fmt.Errorf("config file %s: %s", path, err)
+		}
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	for _, key := range keys {
+		if err := v.BindEnv(key); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			v.Set(f.Name, f.Value.String())
+		}
+	})
+
+	for _, key := range secrets {
+		name := v.GetString(key + "_file")
+		if name == "" {
+			continue
+		}
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s_file: %s", key, err)
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+
+	cfg := &Config{File: path, settings: v.AllSettings()}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, nil, fmt.Errorf("config: %s", err)
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// Validate reports every invalid setting at once, by key.
+func (c *Config) Validate() error {
+	var problems []string
+	invalid := func(key, format string, args ...interface{}) {
+		problems = append(problems, key+" "+fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Address == "" {
+		invalid("server.address", "is required")
+	}
+	durations := map[string]time.Duration{
+		"server.read_header_timeout": c.Server.ReadHeaderTimeout,
+		"server.read_timeout":        c.Server.ReadTimeout,
+		"server.write_timeout":       c.Server.WriteTimeout,
+		"server.idle_timeout":        c.Server.IdleTimeout,
+		"server.drain_delay":         c.Server.DrainDelay,
+		"database.timeout":           c.Database.Timeout,
+	}
+	for operation, timeout := range c.Database.OperationTimeouts {
+		durations["database.operation_timeouts."+operation] = timeout
+	}
+	for key, duration := range durations {
+		if duration < 0 {
+			invalid(key, "must not be negative, got %s", duration)
+		}
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		invalid("server.shutdown_timeout", "must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		invalid("server.max_header_bytes", "must be positive, got %d", c.Server.MaxHeaderBytes)
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		invalid("server.max_body_bytes", "must be positive, got %d", c.Server.MaxBodyBytes)
+	}
+	if c.Server.MaxImportBytes <= 0 {
+		invalid("server.max_import_bytes", "must be positive, got %d", c.Server.MaxImportBytes)
+	}
+
+	for key, value := range map[string]string{
+		"database.host": c.Database.Host,
+		"database.user": c.Database.User,
+		"database.name": c.Database.Name,
+	} {
+		if value == "" {
+			invalid(key, "is required")
+		}
+	}
+	if port, err := strconv.Atoi(c.Database.Port); err != nil || port < 1 || port > 65535 {
+		invalid("database.port", "must be a port number, got %q", c.Database.Port)
+	}
+
+	if c.Sync.Conflict != todo.ConflictLastWriterWins && c.Sync.Conflict != todo.ConflictServerWins {
+		invalid("sync.conflict", "must be %s or %s, got %q", todo.ConflictLastWriterWins, todo.ConflictServerWins, c.Sync.Conflict)
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	default:
+		invalid("tracing.exporter", "must be empty, %s or %s, got %q", tracing.ExporterOTLP, tracing.ExporterStdout, c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	if c.Health.Timeout <= 0 {
+		invalid("health.timeout", "must be positive, got %s", c.Health.Timeout)
+	}
+	if c.Health.Cache < 0 {
+		invalid("health.cache", "must not be negative, got %s", c.Health.Cache)
+	}
+
+	fallback, routes := c.CORS.Policies()
+	if err := fallback.Validate(); err != nil {
+		invalid("cors", "%s", err)
+	}
+	for i, route := range c.CORS.Routes {
+		key := fmt.Sprintf("cors.routes[%d]", i)
+		if !strings.HasPrefix(route.Prefix, "/") {
+			invalid(key+".prefix", "must start with /, got %q", route.Prefix)
+		}
+		if err := routes[route.Prefix].Validate(); err != nil {
+			invalid(key, "%s", err)
+		}
+	}
+
+	if c.Security.HSTSMaxAge < 0 {
+		invalid("security.hsts_max_age", "must not be negative, got %s", c.Security.HSTSMaxAge)
+	}
+	switch c.Security.FrameOptions {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		invalid("security.frame_options", "must be empty, DENY or SAMEORIGIN, got %q", c.Security.FrameOptions)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+}
+
+// Print writes the effective configuration as JSON, in the shape of the
+// config file, with secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	settings := copySettings(c.settings)
+	for _, key := range secrets {
+		path := strings.Split(key, ".")
+		section, ok := settings[path[0]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok := section[path[1]]; ok && fmt.Sprint(value) != "" {
+			section[path[1]] = redacted
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(settings)
+}
+
+func copySettings(settings map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if section, ok := value.(map[string]interface{}); ok {
+			value = copySettings(section)
+		}
+		out[key] = value
+	}
+
+	return out
+}
+
+// trim drops the spaces left by splitting a list from the environment or
+// a flag, such as "GET, POST".
+func trim(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+
+	return out
+}
+
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+func sortedKeys() []string {
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
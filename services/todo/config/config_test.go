@@ -0,0 +1,184 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setenv sets an environment variable until the returned func runs.
+func setenv(t *testing.T, key, value string) func() {
+	t.Helper()
+
+	old, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	return func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+// tempDir makes a directory removed by the returned func.
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(name, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const testFile = `{
+  "server": {"address": ":1000", "read_timeout": "10s"},
+  "database": {"host": "db", "port": "1111", "user": "todo", "name": "todos"},
+  "sync": {"conflict": "server-wins"}
+}`
+
+func TestLoadPrecedence(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	file := filepath.Join(dir, "config.json")
+	writeFile(t, file, testFile)
+
+	defer setenv(t, "TODO_SERVER_ADDRESS", ":2000")()
+	defer setenv(t, "TODO_DATABASE_PORT", "2222")()
+
+	cfg, args, err := Load([]string{"-config", file, "-server.address", ":3000", "migrate", "up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		layer string
+		got   interface{}
+		want  interface{}
+	}{
+		{"default", cfg.Server.WriteTimeout, time.Duration(0)},
+		{"default", cfg.Health.Timeout, time.Second},
+		{"file over default", cfg.Server.ReadTimeout, 10 * time.Second},
+		{"file over default", cfg.Sync.Conflict, "server-wins"},
+		{"env over file", cfg.Database.Port, "2222"},
+		{"flag over env", cfg.Server.Address, ":3000"},
+		{"file", cfg.Database.Name, "todos"},
+		{"file", cfg.File, file},
+		{"args after the flags", args, []string{"migrate", "up"}},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.layer, test.got, test.want)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadNamedFileMustExist(t *testing.T) {
+	if _, _, err := Load([]string{"-config", "does-not-exist.json"}); err == nil {
+		t.Error("a missing config file was accepted")
+	}
+}
+
+func TestLoadListsFromStrings(t *testing.T) {
+	defer setenv(t, "TODO_CORS_ALLOWED_METHODS", "GET, POST")()
+
+	cfg, _, err := Load([]string{"-cors.allowed_origins", "https://a.example, https://b.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fallback, _ := cfg.CORS.Policies()
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(fallback.AllowedOrigins, want) {
+		t.Errorf("allowed origins = %q, want %q", fallback.AllowedOrigins, want)
+	}
+	if want := []string{"GET", "POST"}; !reflect.DeepEqual(fallback.AllowedMethods, want) {
+		t.Errorf("allowed methods = %q, want %q", fallback.AllowedMethods, want)
+	}
+}
+
+func TestSecretFilesAndPrint(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+
+	passFile := filepath.Join(dir, "pass")
+	tokenFile := filepath.Join(dir, "token")
+	file := filepath.Join(dir, "config.json")
+	writeFile(t, passFile, "db-s3cret\n")
+	writeFile(t, tokenFile, "feed-s3cret")
+	writeFile(t, file, `{"calendar": {"token_file": "`+filepath.ToSlash(tokenFile)+`"}}`)
+
+	defer setenv(t, "TODO_DATABASE_PASS", "ignored")()
+	defer setenv(t, "TODO_DATABASE_PASS_FILE", passFile)()
+
+	cfg, _, err := Load([]string{"-config", file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Pass != "db-s3cret" {
+		t.Errorf("database pass = %q, want the file content without its newline", cfg.Database.Pass)
+	}
+	if cfg.Calendar.Token != "feed-s3cret" {
+		t.Errorf("calendar token = %q", cfg.Calendar.Token)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "s3cret") || strings.Count(out.String(), redacted) != 2 {
+		t.Errorf("print does not redact the secrets:\n%s", out.String())
+	}
+	if cfg.Database.Pass != "db-s3cret" {
+		t.Error("print changed the config")
+	}
+
+	if _, _, err := Load([]string{"-config", file, "-database.pass_file", filepath.Join(dir, "missing")}); err == nil || !strings.Contains(err.Error(), "database.pass_file") {
+		t.Errorf("missing secret file: err = %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg, _, err := Load([]string{
+		"-server.address", "",
+		"-database.port", "99999",
+		"-tracing.sample_ratio", "2",
+		"-sync.conflict", "first-wins",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "invalid config: " + strings.Join([]string{
+		"database.host is required",
+		"database.name is required",
+		`database.port must be a port number, got "99999"`,
+		"database.user is required",
+		"server.address is required",
+		`sync.conflict must be last-writer-wins or server-wins, got "first-wins"`,
+		"tracing.sample_ratio must be between 0 and 1, got 2",
+	}, "; ")
+	if err := cfg.Validate(); err == nil || err.Error() != want {
+		t.Errorf("err = %v\nwant %s", err, want)
+	}
+}
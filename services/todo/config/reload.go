@@ -0,0 +1,290 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// reloadable lists the settings a running server picks up, as keys or as
+// prefixes ending in a dot. Changes to any other setting, such as
+// server.address or the database credentials, wait for a restart.
+//
+// The server has no rate limits or feature toggles yet, so there are none
+// to reload; add their keys here when they are introduced.
+var reloadable = []string{
+	"debug",
+	"server.max_body_bytes",
+	"server.max_import_bytes",
+	"server.drain_delay",
+	"server.shutdown_timeout",
+	"database.timeout",
+	"database.operation_timeouts.",
+	"health.timeout",
+	"health.cache",
+	"cors.",
+}
+
+// settleDelay lets an editor finish writing the file before it is read.
+const settleDelay = 100 * time.Millisecond
+
+// Reloadable reports whether a change to key applies without a restart.
+func Reloadable(key string) bool {
+	for _, safe := range reloadable {
+		if key == safe || strings.HasSuffix(safe, ".") && strings.HasPrefix(key, safe) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Change is a setting that differs between two configurations. Secrets
+// show as REDACTED.
+type Change struct {
+	Key     string
+	From    string
+	To      string
+	Restart bool
+}
+
+// Diff lists the settings that differ from old to new, by key.
+func Diff(old, new *Config) []Change {
+	before := flatten(old.settings)
+	after := flatten(new.settings)
+
+	keys := make(map[string]bool, len(after))
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var changes []Change
+	for key := range keys {
+		if fmt.Sprint(before[key]) == fmt.Sprint(after[key]) {
+			continue
+		}
+		changes = append(changes, Change{
+			Key:     key,
+			From:    show(key, before[key]),
+			To:      show(key, after[key]),
+			Restart: !Reloadable(key),
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	return changes
+}
+
+// Reloader holds the configuration the server runs with and reloads it
+// on SIGHUP or when the config file changes.
+type Reloader struct {
+	args    []string
+	current atomic.Value
+
+	mu    sync.Mutex
+	hooks []func(*Config)
+}
+
+// NewReloader starts from cfg, loaded with args, which Reload loads with
+// again so flags keep overriding the file.
+func NewReloader(cfg *Config, args []string) *Reloader {
+	reloader := &Reloader{args: args}
+	reloader.current.Store(cfg)
+	return reloader
+}
+
+// Current returns the configuration in effect. Restart-only settings keep
+// the values the server started with.
+func (c *Reloader) Current() *Config {
+	return c.current.Load().(*Config)
+}
+
+// OnReload registers fn to apply a reloaded configuration to a running
+// component. fn should only read the reloadable settings.
+func (c *Reloader) OnReload(fn func(*Config)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hooks = append(c.hooks, fn)
+}
+
+// Reload loads and validates the configuration again and applies the
+// reloadable changes, returning every change found. An invalid
+// configuration changes nothing.
+func (c *Reloader) Reload() ([]Change, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next, _, err := Load(c.args)
+	if err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	current := c.Current()
+	changes := Diff(current, next)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	applied, err := keepRestartOnly(current, next, changes)
+	if err != nil {
+		return nil, err
+	}
+	c.current.Store(applied)
+	for _, hook := range c.hooks {
+		hook(applied)
+	}
+
+	return changes, nil
+}
+
+// Watch reloads on SIGHUP and whenever the config file changes, logging
+// what changed, until done is closed.
+func (c *Reloader) Watch(done <-chan struct{}) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	file := c.Current().File
+	target, _ := filepath.EvalSymlinks(file)
+	if file != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+
+		// Editors and Kubernetes replace the file instead of writing to
+		// it, so the directory is watched.
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			return err
+		}
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-hup:
+			c.reload("SIGHUP")
+		case event := <-events:
+			// A Kubernetes config map swaps a symlink in the directory,
+			// which shows only as a change of the file's target.
+			changed, _ := filepath.EvalSymlinks(file)
+			written := filepath.Clean(event.Name) == filepath.Clean(file) && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+			if written || changed != "" && changed != target {
+				target = changed
+				settled = time.After(settleDelay)
+			}
+		case <-settled:
+			settled = nil
+			c.reload("file change")
+		case err := <-errs:
+			logrus.Error(err)
+		}
+	}
+}
+
+func (c *Reloader) reload(cause string) {
+	changes, err := c.Reload()
+	if err != nil {
+		logrus.WithField("cause", cause).Errorf("config not reloaded: %s", err)
+		return
+	}
+
+	for _, change := range changes {
+		entry := logrus.WithFields(logrus.Fields{
+			"cause": cause,
+			"key":   change.Key,
+			"from":  change.From,
+			"to":    change.To,
+		})
+		if change.Restart {
+			entry.Warn("config change needs a restart, not applied")
+			continue
+		}
+		entry.Info("config change applied")
+	}
+	if len(changes) == 0 {
+		logrus.WithField("cause", cause).Debug("config reloaded, nothing changed")
+	}
+}
+
+// keepRestartOnly returns next with the restart-only settings of current.
+func keepRestartOnly(current, next *Config, changes []Change) (*Config, error) {
+	settings := flatten(next.settings)
+	before := flatten(current.settings)
+	for _, change := range changes {
+		if !change.Restart {
+			continue
+		}
+		if value, ok := before[change.Key]; ok {
+			settings[change.Key] = value
+		} else {
+			delete(settings, change.Key)
+		}
+	}
+
+	v := viper.New()
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+
+	applied := &Config{File: next.File, settings: v.AllSettings()}
+	if err := v.Unmarshal(applied); err != nil {
+		return nil, fmt.Errorf("config: %s", err)
+	}
+
+	return applied, nil
+}
+
+func flatten(settings map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	var walk func(prefix string, settings map[string]interface{})
+	walk = func(prefix string, settings map[string]interface{}) {
+		for key, value := range settings {
+			if section, ok := value.(map[string]interface{}); ok {
+				walk(prefix+key+".", section)
+				continue
+			}
+			flat[prefix+key] = value
+		}
+	}
+	walk("", settings)
+
+	return flat
+}
+
+func show(key string, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	for _, secret := range secrets {
+		if key == secret && fmt.Sprint(value) != "" {
+			return redacted
+		}
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const reloadFile = `{
+  "debug": false,
+  "server": {"address": ":1000"},
+  "database": {"host": "db", "user": "todo", "pass": "old-s3cret", "name": "todos"},
+  "cors": {"allowed_origins": ["https://a.example"]}
+}`
+
+func newTestReloader(t *testing.T, file string) *Reloader {
+	t.Helper()
+
+	args := []string{"-config", file}
+	cfg, _, err := Load(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewReloader(cfg, args)
+}
+
+func TestReloadAppliesReloadableChanges(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	file := filepath.Join(dir, "config.json")
+	writeFile(t, file, reloadFile)
+
+	reloader := newTestReloader(t, file)
+	var hooked []*Config
+	reloader.OnReload(func(cfg *Config) { hooked = append(hooked, cfg) })
+
+	writeFile(t, file, `{
+  "debug": true,
+  "server": {"address": ":2000"},
+  "database": {"host": "db", "user": "todo", "pass": "new-s3cret", "name": "todos"},
+  "cors": {"allowed_origins": ["https://b.example"]}
+}`)
+	changes, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Key: "cors.allowed_origins", From: "[https://a.example]", To: "[https://b.example]"},
+		{Key: "database.pass", From: redacted, To: redacted, Restart: true},
+		{Key: "debug", From: "false", To: "true"},
+		{Key: "server.address", From: ":1000", To: ":2000", Restart: true},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v\nwant %+v", changes, want)
+	}
+
+	current := reloader.Current()
+	if !current.Debug || !reflect.DeepEqual(current.CORS.AllowedOrigins, []string{"https://b.example"}) {
+		t.Errorf("reloadable settings not applied: debug %v, origins %v", current.Debug, current.CORS.AllowedOrigins)
+	}
+	if current.Server.Address != ":1000" || current.Database.Pass != "old-s3cret" {
+		t.Errorf("restart-only settings changed: address %q, pass %q", current.Server.Address, current.Database.Pass)
+	}
+	if len(hooked) != 1 || hooked[0] != current {
+		t.Errorf("hooks ran %d times", len(hooked))
+	}
+
+	if changes, err := reloader.Reload(); err != nil || len(changes) != 2 {
+		t.Errorf("reloading the same file again: %+v, %v; want only the restart-only changes", changes, err)
+	}
+}
+
+func TestReloadIgnoresAnInvalidFile(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	file := filepath.Join(dir, "config.json")
+	writeFile(t, file, reloadFile)
+
+	reloader := newTestReloader(t, file)
+	before := reloader.Current()
+	hooked := 0
+	reloader.OnReload(func(*Config) { hooked++ })
+
+	for _, content := range []string{
+		`{"debug": true, "database": {"host": "db", "user": "todo", "name": "todos"}, "sync": {"conflict": "first-wins"}}`,
+		`{"debug": true,`,
+	} {
+		writeFile(t, file, content)
+		if _, err := reloader.Reload(); err == nil {
+			t.Errorf("%s: reloaded", content)
+		}
+	}
+
+	if reloader.Current() != before || hooked != 0 {
+		t.Error("an invalid file changed the config")
+	}
+}
+
+func TestDiffRedactsSecrets(t *testing.T) {
+	old := &Config{settings: map[string]interface{}{
+		"calendar": map[string]interface{}{"token": ""},
+		"database": map[string]interface{}{"pass": "old-s3cret", "user": "todo"},
+	}}
+	new := &Config{settings: map[string]interface{}{
+		"calendar": map[string]interface{}{"token": "feed-s3cret"},
+		"database": map[string]interface{}{"pass": "new-s3cret", "user": "admin"},
+	}}
+
+	want := []Change{
+		{Key: "calendar.token", From: "", To: redacted, Restart: true},
+		{Key: "database.pass", From: redacted, To: redacted, Restart: true},
+		{Key: "database.user", From: "todo", To: "admin", Restart: true},
+	}
+	if changes := Diff(old, new); !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v\nwant %+v", changes, want)
+	}
+}
@@ -0,0 +1,627 @@
+// Package caldav implements the part of CalDAV (RFC 4791) that task apps
+// need to sync todos: discovery with PROPFIND, the calendar-query and
+// calendar-multiget reports, and GET, PUT and DELETE of single VTODO
+// resources guarded by ETags.
+//
+// Todos have no lists in this tree, so there is one calendar,
+// /dav/calendars/todos/, holding every todo. /dav/ is both the root and
+// the principal. There is no authentication, as for the rest of the API.
+package caldav
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	httpResponse "github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/services/todo/codec"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+)
+
+const (
+	rootPath     = "/dav/"
+	homePath     = "/dav/calendars/"
+	calendarPath = "/dav/calendars/todos/"
+
+	maxBody = 1 << 20
+)
+
+type kind int
+
+const (
+	kindRoot kind = iota
+	kindHome
+	kindCalendar
+	kindObject
+)
+
+// generatedUID matches the UIDs codec.ICSUID derives from a todo id.
+var generatedUID = regexp.MustCompile(`^todo-([0-9]+)@todo-crud$`)
+
+type resource struct {
+	kind kind
+	href string
+	todo *todo.ViewResponse
+	// ctag changes whenever any todo in the calendar does.
+	ctag string
+}
+
+type TodoHandler struct {
+	TodoService service.Service
+}
+
+// Methods are the methods the DAV tree answers.
+var Methods = []string{http.MethodOptions, "PROPFIND", "REPORT", http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete}
+
+// DiscoveryMethods are the methods clients send to /.well-known/caldav
+// to find the DAV tree.
+var DiscoveryMethods = []string{http.MethodOptions, "PROPFIND", http.MethodGet, http.MethodHead}
+
+// NewTodoHandler registers /.well-known/caldav and the DAV tree under
+// /dav/.
+func NewTodoHandler(r *mux.Router, todoService service.Service) {
+	handler := &TodoHandler{
+		TodoService: todoService,
+	}
+
+	r.Handle("/.well-known/caldav", http.RedirectHandler(rootPath, http.StatusMovedPermanently)).Methods(DiscoveryMethods...)
+	r.PathPrefix(rootPath).HandlerFunc(handler.ServeDAV).Methods(Methods...)
+}
+
+func (c *TodoHandler) ServeDAV(w http.ResponseWriter, r *http.Request) {
+	p := r.URL.Path
+	if !strings.HasSuffix(p, "/") && !strings.HasSuffix(p, ".ics") {
+		p += "/"
+	}
+
+	var k kind
+	switch {
+	case p == rootPath:
+		k = kindRoot
+	case p == homePath:
+		k = kindHome
+	case p == calendarPath:
+		k = kindCalendar
+	case path.Dir(p)+"/" == calendarPath && strings.HasSuffix(p, ".ics"):
+		k = kindObject
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		c.Options(w, r)
+	case "PROPFIND":
+		c.Propfind(w, r, k, p)
+	case "REPORT":
+		if k != kindCalendar {
+			http.Error(w, "reports are only supported on the calendar", http.StatusMethodNotAllowed)
+			return
+		}
+		c.Report(w, r)
+	case http.MethodGet, http.MethodHead:
+		if k != kindObject {
+			http.Error(w, "collections have no body", http.StatusMethodNotAllowed)
+			return
+		}
+		c.Get(w, r, p)
+	case http.MethodPut:
+		if k != kindObject {
+			http.Error(w, "only calendar objects can be written", http.StatusMethodNotAllowed)
+			return
+		}
+		c.Put(w, r, p)
+	case http.MethodDelete:
+		if k != kindObject {
+			http.Error(w, "only calendar objects can be deleted", http.StatusMethodNotAllowed)
+			return
+		}
+		c.Delete(w, r, p)
+	default:
+		w.Header().Set("Allow", allow)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+var allow = strings.Join(Methods, ", ")
+
+func (c *TodoHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", allow)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *TodoHandler) Propfind(w http.ResponseWriter, r *http.Request, k kind, p string) {
+	req, err := parseRequest(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	depth := r.Header.Get("Depth")
+	if depth == "" {
+		depth = "infinity"
+	}
+
+	resources := make([]resource, 0)
+	switch k {
+	case kindRoot:
+		resources = append(resources, resource{kind: kindRoot, href: rootPath})
+		if depth != "0" {
+			resources = append(resources, resource{kind: kindHome, href: homePath})
+		}
+	case kindHome:
+		resources = append(resources, resource{kind: kindHome, href: homePath})
+		if depth != "0" {
+			calendar, _, err := c.calendar(r, false)
+			if err != nil {
+				http.Error(w, err.Error(), statusOf(err))
+				return
+			}
+			resources = append(resources, calendar)
+		}
+	case kindCalendar:
+		calendar, objects, err := c.calendar(r, depth != "0")
+		if err != nil {
+			http.Error(w, err.Error(), statusOf(err))
+			return
+		}
+		resources = append(append(resources, calendar), objects...)
+	case kindObject:
+		object, err := c.object(r, p)
+		if err != nil {
+			http.Error(w, err.Error(), statusOf(err))
+			return
+		}
+		if object == nil {
+			http.NotFound(w, r)
+			return
+		}
+		resources = append(resources, *object)
+	}
+
+	responses := make([]response, 0, len(resources))
+	for _, res := range resources {
+		responses = append(responses, c.propfind(res, req))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+func (c *TodoHandler) Report(w http.ResponseWriter, r *http.Request) {
+	req, err := parseRequest(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	responses := make([]response, 0)
+	switch {
+	case is(req.root, nsCalDAV, "calendar-query"):
+		// Only component filters are honoured; time ranges and property
+		// filters are left to the client.
+		for _, name := range req.compFilters {
+			if name != "VCALENDAR" && name != "VTODO" {
+				writeMultistatus(w, responses)
+				return
+			}
+		}
+
+		_, objects, err := c.calendar(r, true)
+		if err != nil {
+			http.Error(w, err.Error(), statusOf(err))
+			return
+		}
+
+		for _, object := range objects {
+			responses = append(responses, c.propfind(object, req))
+		}
+	case is(req.root, nsCalDAV, "calendar-multiget"):
+		for _, h := range req.hrefs {
+			p := h
+			if u, err := url.Parse(h); err == nil {
+				p = u.Path
+			}
+
+			object, err := c.object(r, p)
+			if err != nil {
+				http.Error(w, err.Error(), statusOf(err))
+				return
+			}
+			if object == nil {
+				responses = append(responses, response{href: h, status: http.StatusNotFound})
+				continue
+			}
+
+			responses = append(responses, c.propfind(*object, req))
+		}
+	default:
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `<?xml version="1.0" encoding="utf-8"?>`+"\n"+`<d:error xmlns:d="DAV:"><d:supported-report/></d:error>`+"\n")
+		return
+	}
+
+	writeMultistatus(w, responses)
+}
+
+func (c *TodoHandler) Get(w http.ResponseWriter, r *http.Request, p string) {
+	object, err := c.object(r, p)
+	if err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+	if object == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := render(object.todo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", codec.ContentType(codec.FormatICS))
+	w.Header().Set("ETag", etag(object.todo))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(body)
+	}
+}
+
+// Put creates or replaces the todo behind p. A new resource must be named
+// after its UID, which becomes the todo's external id. No ETag is
+// returned, since only some of the VTODO is stored and clients have to
+// fetch what the server kept.
+func (c *TodoHandler) Put(w http.ResponseWriter, r *http.Request, p string) {
+	dec, _ := codec.NewDecoder(codec.FormatICS, io.LimitReader(r.Body, maxBody), nil)
+	record, err := dec.Decode()
+	if err == io.EOF {
+		http.Error(w, "body has no VTODO", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	object, err := c.object(r, p)
+	if err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+
+	if !preconditions(w, r, object) {
+		return
+	}
+
+	update := &todo.UpdateRequest{
+		Title:       record.Title,
+		Description: record.Description,
+		IsDone:      strconv.FormatBool(record.IsDone),
+		IsFavorite:  strconv.FormatBool(record.IsFavorite),
+	}
+
+	if object != nil {
+		if _, err := c.TodoService.UpdateData(r.Context(), int(object.todo.ID), update); err != nil {
+			http.Error(w, err.Error(), statusOf(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if record.ExternalID != name(p) {
+		http.Error(w, "the resource name must be the UID followed by .ics", http.StatusBadRequest)
+		return
+	}
+
+	created, err := c.TodoService.Create(r.Context(), &todo.CreateRequest{
+		Title:       record.Title,
+		Description: record.Description,
+		ExternalID:  record.ExternalID,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+
+	if record.IsDone || record.IsFavorite {
+		if _, err := c.TodoService.UpdateData(r.Context(), int(created.ID), update); err != nil {
+			http.Error(w, err.Error(), statusOf(err))
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (c *TodoHandler) Delete(w http.ResponseWriter, r *http.Request, p string) {
+	object, err := c.object(r, p)
+	if err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+	if object == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !preconditions(w, r, object) {
+		return
+	}
+
+	if err := c.TodoService.DeleteByID(r.Context(), int(object.todo.ID)); err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// calendar returns the calendar collection and, when withObjects is set,
+// a resource per todo.
+func (c *TodoHandler) calendar(r *http.Request, withObjects bool) (resource, []resource, error) {
+	todos, err := c.TodoService.GetAll(r.Context(), map[string]interface{}{
+		"is_done":     "",
+		"is_favorite": "",
+	})
+	if err != nil {
+		return resource{}, nil, err
+	}
+
+	hash := sha1.New()
+	objects := make([]resource, 0, len(todos))
+	for i := range todos {
+		_, _ = io.WriteString(hash, etag(&todos[i]))
+		if withObjects {
+			objects = append(objects, objectResource(&todos[i]))
+		}
+	}
+
+	calendar := resource{
+		kind: kindCalendar,
+		href: calendarPath,
+		ctag: hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	return calendar, objects, nil
+}
+
+// object looks up the todo a resource path names. It returns nil when
+// there is none.
+func (c *TodoHandler) object(r *http.Request, p string) (*resource, error) {
+	if path.Dir(p)+"/" != calendarPath || !strings.HasSuffix(p, ".ics") {
+		return nil, nil
+	}
+
+	uid := name(p)
+
+	todos, err := c.TodoService.GetByExternalIDs(r.Context(), []string{uid})
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) > 0 {
+		object := objectResource(&todos[0])
+		return &object, nil
+	}
+
+	match := generatedUID.FindStringSubmatch(uid)
+	if match == nil {
+		return nil, nil
+	}
+
+	id, _ := strconv.Atoi(match[1])
+	data, err := c.TodoService.GetByID(r.Context(), id)
+	if err == repository.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A todo with an external id lives under that name only.
+	if data.ExternalID != "" {
+		return nil, nil
+	}
+
+	object := objectResource(data)
+	return &object, nil
+}
+
+func objectResource(data *todo.ViewResponse) resource {
+	return resource{
+		kind: kindObject,
+		href: calendarPath + url.PathEscape(codec.ICSUID(data)) + ".ics",
+		todo: data,
+	}
+}
+
+// name returns the resource name of p without .ics.
+func name(p string) string {
+	return strings.TrimSuffix(path.Base(p), ".ics")
+}
+
+// preconditions applies If-Match and If-None-Match, writing 412 when they
+// fail.
+func preconditions(w http.ResponseWriter, r *http.Request, object *resource) bool {
+	ok := true
+	if match := r.Header.Get("If-Match"); match != "" {
+		ok = object != nil && (match == "*" || matches(match, etag(object.todo)))
+	}
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" && object != nil {
+		ok = ok && noneMatch != "*" && !matches(noneMatch, etag(object.todo))
+	}
+
+	if !ok {
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+	}
+
+	return ok
+}
+
+func matches(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// etag is derived from the stored fields rather than UpdatedAt, whose
+// precision differs between Go and the database.
+func etag(data *todo.ViewResponse) string {
+	hash := sha1.New()
+	for _, field := range []string{
+		strconv.FormatUint(uint64(data.ID), 10),
+		data.ExternalID,
+		data.Title,
+		data.Description,
+		strconv.FormatBool(data.IsDone),
+		strconv.FormatBool(data.IsFavorite),
+	} {
+		_, _ = io.WriteString(hash, field)
+		_, _ = hash.Write([]byte{0})
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+}
+
+func render(data *todo.ViewResponse) ([]byte, error) {
+	var b bytes.Buffer
+	enc, _ := codec.NewEncoder(codec.FormatICS, &b)
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// statusOf maps service errors: repository failures are the server's
+// fault, anything else is a validation error.
+func statusOf(err error) int {
+	switch err {
+	case repository.ErrNotFound:
+		return http.StatusNotFound
+	case repository.ErrTimeout:
+		return http.StatusServiceUnavailable
+	case repository.ErrCanceled:
+		return httpResponse.StatusClientClosedRequest
+	case repository.ErrCreate, repository.ErrSave, repository.ErrGet, repository.ErrGetChanged, repository.ErrDelete:
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusBadRequest
+}
+
+func (c *TodoHandler) propfind(res resource, req *request) response {
+	resp := response{href: res.href}
+
+	names := req.props
+	if req.allProps {
+		names = allProps
+	}
+
+	for _, n := range names {
+		inner, ok := c.property(res, n)
+		if ok {
+			resp.found = append(resp.found, property{name: n, inner: inner})
+		} else if !req.allProps {
+			resp.missing = append(resp.missing, n)
+		}
+	}
+
+	return resp
+}
+
+var allProps = []xml.Name{
+	{Space: nsDAV, Local: "resourcetype"},
+	{Space: nsDAV, Local: "displayname"},
+	{Space: nsDAV, Local: "getetag"},
+	{Space: nsDAV, Local: "getcontenttype"},
+	{Space: nsCalendarServer, Local: "getctag"},
+}
+
+// property renders one property of res, or reports that res does not
+// have it.
+func (c *TodoHandler) property(res resource, n xml.Name) (string, bool) {
+	switch n.Space + " " + n.Local {
+	case nsDAV + " resourcetype":
+		switch res.kind {
+		case kindRoot:
+			return "<d:collection/><d:principal/>", true
+		case kindHome:
+			return "<d:collection/>", true
+		case kindCalendar:
+			return "<d:collection/><c:calendar/>", true
+		}
+		return "", true
+	case nsDAV + " displayname":
+		switch res.kind {
+		case kindRoot:
+			return "todo", true
+		case kindHome:
+			return "calendars", true
+		case kindCalendar:
+			return "Todos", true
+		}
+		return xmlEscape(res.todo.Title), true
+	case nsDAV + " current-user-principal":
+		return href(rootPath), true
+	case nsDAV + " principal-URL":
+		return href(rootPath), res.kind == kindRoot
+	case nsCalDAV + " calendar-home-set":
+		return href(homePath), res.kind == kindRoot
+	case nsDAV + " current-user-privilege-set":
+		return "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>" +
+			"<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege>" +
+			"<d:privilege><d:unbind/></d:privilege>", true
+	case nsCalDAV + " supported-calendar-component-set":
+		return `<c:comp name="VTODO"/>`, res.kind == kindCalendar
+	case nsDAV + " supported-report-set":
+		return "<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>" +
+			"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>", res.kind == kindCalendar
+	case nsCalendarServer + " getctag":
+		return xmlEscape(res.ctag), res.kind == kindCalendar
+	case nsDAV + " getetag":
+		if res.kind != kindObject {
+			return "", false
+		}
+		return xmlEscape(etag(res.todo)), true
+	case nsDAV + " getcontenttype":
+		if res.kind != kindObject {
+			return "", false
+		}
+		return "text/calendar; charset=utf-8; component=VTODO", true
+	case nsCalDAV + " calendar-data":
+		if res.kind != kindObject {
+			return "", false
+		}
+		body, err := render(res.todo)
+		if err != nil {
+			return "", false
+		}
+		return xmlEscape(string(body)), true
+	}
+
+	return "", false
+}
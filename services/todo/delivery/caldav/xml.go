@@ -0,0 +1,181 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+)
+
+var prefixes = map[string]string{
+	nsDAV:            "d",
+	nsCalDAV:         "c",
+	nsCalendarServer: "cs",
+}
+
+// request is what PROPFIND and REPORT bodies are reduced to: the root
+// element, the properties asked for, and for reports the hrefs and
+// component filters.
+type request struct {
+	root        xml.Name
+	allProps    bool
+	props       []xml.Name
+	hrefs       []string
+	compFilters []string
+}
+
+// parseRequest reads a PROPFIND or REPORT body. An empty body asks for
+// all properties, as RFC 4918 specifies for PROPFIND.
+func parseRequest(body io.Reader) (*request, error) {
+	req := new(request)
+	d := xml.NewDecoder(body)
+
+	// path holds the local names of the open elements.
+	path := make([]xml.Name, 0, 8)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid xml body: %s", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case len(path) == 0:
+				req.root = t.Name
+			case len(path) == 1 && is(t.Name, nsDAV, "allprop"), len(path) == 1 && is(t.Name, nsDAV, "propname"):
+				req.allProps = true
+			case len(path) == 2 && is(path[1], nsDAV, "prop"):
+				req.props = append(req.props, t.Name)
+			case len(path) == 1 && is(t.Name, nsDAV, "href"):
+				var href string
+				if err := d.DecodeElement(&href, &t); err != nil {
+					return nil, fmt.Errorf("invalid xml body: %s", err)
+				}
+				req.hrefs = append(req.hrefs, strings.TrimSpace(href))
+				continue
+			case is(t.Name, nsCalDAV, "comp-filter"):
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "name" {
+						req.compFilters = append(req.compFilters, strings.ToUpper(attr.Value))
+					}
+				}
+			}
+			path = append(path, t.Name)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+	}
+
+	if req.root.Local == "" {
+		req.allProps = true
+	}
+
+	return req, nil
+}
+
+func is(name xml.Name, space, local string) bool {
+	return name.Space == space && name.Local == local
+}
+
+// property is a rendered property value; inner is already XML.
+type property struct {
+	name  xml.Name
+	inner string
+}
+
+type response struct {
+	href    string
+	status  int
+	found   []property
+	missing []xml.Name
+}
+
+// writeMultistatus writes a 207 Multi-Status body.
+func writeMultistatus(w http.ResponseWriter, responses []response) {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+
+	for _, resp := range responses {
+		b.WriteString("<d:response><d:href>")
+		escape(&b, resp.href)
+		b.WriteString("</d:href>")
+
+		if resp.status != 0 {
+			fmt.Fprintf(&b, "<d:status>%s</d:status>", statusLine(resp.status))
+		}
+
+		if len(resp.found) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, p := range resp.found {
+				element(&b, p.name, p.inner)
+			}
+			fmt.Fprintf(&b, "</d:prop><d:status>%s</d:status></d:propstat>", statusLine(http.StatusOK))
+		}
+
+		if len(resp.missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range resp.missing {
+				element(&b, name, "")
+			}
+			fmt.Fprintf(&b, "</d:prop><d:status>%s</d:status></d:propstat>", statusLine(http.StatusNotFound))
+		}
+
+		b.WriteString("</d:response>")
+	}
+
+	b.WriteString("</d:multistatus>\n")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write(b.Bytes())
+}
+
+// element writes <name>inner</name>, declaring the namespace inline when
+// it has no prefix on the multistatus element.
+func element(b *bytes.Buffer, name xml.Name, inner string) {
+	tag, declaration := name.Local, ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		declaration = ` xmlns:x="` + xmlEscape(name.Space) + `"`
+	}
+
+	if inner == "" {
+		fmt.Fprintf(b, "<%s%s/>", tag, declaration)
+		return
+	}
+
+	fmt.Fprintf(b, "<%s%s>%s</%s>", tag, declaration, inner, tag)
+}
+
+func statusLine(status int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", status, http.StatusText(status))
+}
+
+func escape(b *bytes.Buffer, s string) {
+	_ = xml.EscapeText(b, []byte(s))
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	escape(&b, s)
+	return b.String()
+}
+
+func href(path string) string {
+	return "<d:href>" + xmlEscape(path) + "</d:href>"
+}
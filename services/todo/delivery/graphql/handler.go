@@ -0,0 +1,150 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/message"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+type TodoHandler struct {
+	TodoService   service.Service
+	schema        graphql.Schema
+	jsonResponder response.JSONResponder
+}
+
+func NewTodoHandler(r *mux.Router, todoService service.Service, broker *event.Broker) error {
+	schema, err := NewSchema(todoService, broker)
+	if err != nil {
+		return err
+	}
+
+	handler := &TodoHandler{
+		TodoService:   todoService,
+		schema:        schema,
+		jsonResponder: response.NewDefaultJSONResponder(),
+	}
+
+	r.HandleFunc("/graphql", handler.Serve).Methods(http.MethodGet, http.MethodPost)
+
+	return nil
+}
+
+// Serve executes queries and mutations and answers with a plain GraphQL
+// result. Subscriptions are streamed as server-sent events when the client
+// accepts text/event-stream. GET only runs queries: a GET must not change
+// anything, and any page can make the browser send one.
+func (c *TodoHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	req := new(Request)
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "invalid variables"))
+				return
+			}
+		}
+
+		if operation := operationType(req.Query, req.OperationName); operation != "" && operation != ast.OperationTypeQuery {
+			w.Header().Set("Allow", http.MethodPost)
+			c.jsonResponder.Error(w, http.StatusMethodNotAllowed, message.NewErrorMessage(0, operation+" should be sent with POST"))
+			return
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "invalid json body"))
+		return
+	}
+
+	ctx := withLoader(r.Context(), newLoader(r.Context(), c.TodoService))
+	params := graphql.Params{
+		Schema:         c.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		c.subscribe(w, r, params)
+		return
+	}
+
+	c.jsonResponder.Write(w, http.StatusOK, graphql.Do(params))
+	return
+}
+
+func (c *TodoHandler) subscribe(w http.ResponseWriter, r *http.Request, params graphql.Params) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		c.jsonResponder.Error(w, http.StatusInternalServerError, message.NewErrorMessage(0, "streaming is not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Keep draining the channel after a write error so the subscription
+	// goroutine is never left blocked; it closes once the request ends.
+	broken := false
+	for result := range graphql.Subscribe(params) {
+		if broken {
+			continue
+		}
+
+		content, err := json.Marshal(result)
+		if err != nil {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", content); err != nil {
+			broken = true
+			continue
+		}
+		flusher.Flush()
+	}
+}
+
+// operationType returns the type of the operation graphql.Do would run for
+// query and operationName. It is empty when there is none, because the
+// query does not parse or the operation cannot be picked; graphql.Do then
+// answers with the error without running anything.
+func operationType(query, operationName string) string {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return ""
+	}
+
+	var operations []*ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || operation.Name != nil && operation.Name.Value == operationName {
+			operations = append(operations, operation)
+		}
+	}
+	if len(operations) != 1 {
+		return ""
+	}
+
+	return operations[0].Operation
+}
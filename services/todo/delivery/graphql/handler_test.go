@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	"github.com/jinzhu/gorm"
+)
+
+// fakeService serves GetByIDs and GetAll from todos and counts the calls
+// that would change something.
+type fakeService struct {
+	service.Service
+	todos   []todo.ViewResponse
+	changes int
+	lookups int
+	err     error
+}
+
+func (c *fakeService) GetByIDs(ctx context.Context, ids []int) ([]todo.ViewResponse, error) {
+	c.lookups++
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	var found []todo.ViewResponse
+	for _, data := range c.todos {
+		for _, id := range ids {
+			if int(data.ID) == id {
+				found = append(found, data)
+			}
+		}
+	}
+
+	return found, nil
+}
+
+func (c *fakeService) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	return c.todos, nil
+}
+
+func (c *fakeService) DeleteByID(ctx context.Context, id int) error {
+	c.changes++
+	return nil
+}
+
+func newFakeService() *fakeService {
+	return &fakeService{todos: []todo.ViewResponse{{Model: gorm.Model{ID: 1}, Title: "buy milk"}}}
+}
+
+func TestGetOnlyRunsQueries(t *testing.T) {
+	todoService := newFakeService()
+	r := mux.NewRouter()
+	if err := NewTodoHandler(r, todoService, event.NewBroker()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query         string
+		operationName string
+		status        int
+	}{
+		{`{ todo(id: 1) { title } }`, "", http.StatusOK},
+		{`query { todos { id } }`, "", http.StatusOK},
+		{`mutation { deleteTodo(id: 1) }`, "", http.StatusMethodNotAllowed},
+		{`query a { todos { id } } mutation b { deleteTodo(id: 1) }`, "b", http.StatusMethodNotAllowed},
+		{`query a { todos { id } } mutation b { deleteTodo(id: 1) }`, "a", http.StatusOK},
+		{`subscription { todoChanged { type } }`, "", http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		target := "/graphql?query=" + url.QueryEscape(test.query) + "&operationName=" + test.operationName
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if w.Code != test.status {
+			t.Errorf("GET %s: status = %d, want %d: %s", test.query, w.Code, test.status, w.Body)
+		}
+	}
+	if todoService.changes != 0 {
+		t.Errorf("GET ran %d mutations", todoService.changes)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "mutation { deleteTodo(id: 1) }"}`)))
+	if w.Code != http.StatusOK || todoService.changes != 1 {
+		t.Errorf("POST mutation: status = %d, changes = %d", w.Code, todoService.changes)
+	}
+}
+
+func TestSchemaWithoutLoader(t *testing.T) {
+	schema, err := NewSchema(newFakeService(), event.NewBroker())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ todo(id: 1) { title } todos { id } }`,
+		Context:       context.Background(),
+	})
+	if result.HasErrors() {
+		t.Fatal(result.Errors)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if title := data["todo"].(map[string]interface{})["title"]; title != "buy milk" {
+		t.Errorf("title = %v, want buy milk", title)
+	}
+}
+
+func TestBatchErrorReachesEveryTodo(t *testing.T) {
+	todoService := newFakeService()
+	todoService.err = errors.New("database is down")
+	r := mux.NewRouter()
+	if err := NewTodoHandler(r, todoService, event.NewBroker()); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ a: todo(id: 1) { title } b: todo(id: 2) { title } }"}`)))
+
+	var resp struct {
+		Data   map[string]interface{}
+		Errors []struct {
+			Message string
+			Path    []string
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if todoService.lookups != 1 {
+		t.Errorf("GetByIDs ran %d times, want one batch", todoService.lookups)
+	}
+
+	paths := map[string]bool{}
+	for _, e := range resp.Errors {
+		if e.Message == "database is down" && len(e.Path) == 1 {
+			paths[e.Path[0]] = true
+		}
+	}
+	if !paths["a"] || !paths["b"] {
+		t.Errorf("errors = %+v, want the batch error for a and b", resp.Errors)
+	}
+}
@@ -0,0 +1,99 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+type loaderKey struct{}
+
+// loader batches todo lookups made while resolving one request. Resolvers
+// register ids and return a thunk; the first thunk to run loads every
+// pending id with a single GetByIDs call. When the call fails, every
+// thunk of the batch returns its error.
+type loader struct {
+	ctx         context.Context
+	todoService service.Service
+
+	mu      sync.Mutex
+	pending []int
+	results map[int]*todo.ViewResponse
+	errs    map[int]error
+}
+
+func newLoader(ctx context.Context, todoService service.Service) *loader {
+	return &loader{
+		ctx:         ctx,
+		todoService: todoService,
+		results:     make(map[int]*todo.ViewResponse),
+		errs:        make(map[int]error),
+	}
+}
+
+func withLoader(ctx context.Context, l *loader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+// loaderFrom returns the loader of the request, or a new one when the
+// schema runs outside Serve and no request set one up.
+func loaderFrom(ctx context.Context, todoService service.Service) *loader {
+	if l, ok := ctx.Value(loaderKey{}).(*loader); ok {
+		return l
+	}
+
+	return newLoader(ctx, todoService)
+}
+
+func (c *loader) load(id int) func() (interface{}, error) {
+	c.mu.Lock()
+	_, loaded := c.results[id]
+	_, failed := c.errs[id]
+	if !loaded && !failed {
+		c.pending = append(c.pending, id)
+	}
+	c.mu.Unlock()
+
+	return func() (interface{}, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if len(c.pending) > 0 {
+			ids := c.pending
+			c.pending = nil
+
+			data, err := c.todoService.GetByIDs(c.ctx, ids)
+			for _, id := range ids {
+				if err != nil {
+					c.errs[id] = err
+				} else {
+					c.results[id] = nil
+				}
+			}
+			for i := range data {
+				c.results[int(data[i].ID)] = &data[i]
+			}
+		}
+
+		if err := c.errs[id]; err != nil {
+			return nil, err
+		}
+
+		if result := c.results[id]; result != nil {
+			return result, nil
+		}
+
+		return nil, nil
+	}
+}
+
+// prime stores a todo that was already loaded so later lookups of the same
+// id in the request do not hit the repository again.
+func (c *loader) prime(data *todo.ViewResponse) {
+	c.mu.Lock()
+	c.results[int(data.ID)] = data
+	delete(c.errs, int(data.ID))
+	c.mu.Unlock()
+}
@@ -0,0 +1,325 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/graphql-go/graphql"
+)
+
+type resolver struct {
+	todoService service.Service
+	broker      *event.Broker
+}
+
+func NewSchema(todoService service.Service, broker *event.Broker) (graphql.Schema, error) {
+	c := &resolver{
+		todoService: todoService,
+		broker:      broker,
+	}
+
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return int(p.Source.(*todo.ViewResponse).ID), nil
+				},
+			},
+			"title": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*todo.ViewResponse).Title, nil
+				},
+			},
+			"description": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*todo.ViewResponse).Description, nil
+				},
+			},
+			"isDone": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*todo.ViewResponse).IsDone, nil
+				},
+			},
+			"isFavorite": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*todo.ViewResponse).IsFavorite, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*todo.ViewResponse).CreatedAt, nil
+				},
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*todo.ViewResponse).UpdatedAt, nil
+				},
+			},
+		},
+	})
+
+	todoEventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoEvent",
+		Fields: graphql.Fields{
+			"type": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(event.Event).Type), nil
+				},
+			},
+			"todo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					e := p.Source.(event.Event)
+					return &e.Todo, nil
+				},
+			},
+		},
+	})
+
+	todoList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType)))
+	id := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todo": &graphql.Field{
+				Type:    todoType,
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: c.todo,
+			},
+			"todos": &graphql.Field{
+				Type: todoList,
+				Args: graphql.FieldConfigArgument{
+					"isDone":     &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"isFavorite": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: c.todos,
+			},
+			"searchTodos": &graphql.Field{
+				Type: todoList,
+				Args: graphql.FieldConfigArgument{
+					"title": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: c.searchTodos,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"title":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: c.createTodo,
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":          id,
+					"title":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"isDone":      &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"isFavorite":  &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: c.updateTodo,
+			},
+			"markDone": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":     id,
+					"isDone": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Boolean)},
+				},
+				Resolve: c.markDone,
+			},
+			"markFavorite": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":         id,
+					"isFavorite": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Boolean)},
+				},
+				Resolve: c.markFavorite,
+			},
+			"deleteTodo": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: c.deleteTodo,
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"todoChanged": &graphql.Field{
+				Type: graphql.NewNonNull(todoEventType),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: c.todoChanged,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
+
+func (c *resolver) todo(p graphql.ResolveParams) (interface{}, error) {
+	return loaderFrom(p.Context, c.todoService).load(p.Args["id"].(int)), nil
+}
+
+func (c *resolver) todos(p graphql.ResolveParams) (interface{}, error) {
+	params := map[string]interface{}{
+		"is_done":     boolArg(p.Args, "isDone"),
+		"is_favorite": boolArg(p.Args, "isFavorite"),
+	}
+
+	resp, err := c.todoService.GetAll(p.Context, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.prime(p.Context, resp), nil
+}
+
+func (c *resolver) searchTodos(p graphql.ResolveParams) (interface{}, error) {
+	params := map[string]interface{}{
+		"title": p.Args["title"].(string),
+	}
+
+	resp, err := c.todoService.GetByTitle(p.Context, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.prime(p.Context, resp), nil
+}
+
+func (c *resolver) createTodo(p graphql.ResolveParams) (interface{}, error) {
+	resp, err := c.todoService.Create(p.Context, &todo.CreateRequest{
+		Title:       p.Args["title"].(string),
+		Description: p.Args["description"].(string),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	view := todo.ViewResponse(*resp)
+	return &view, nil
+}
+
+func (c *resolver) updateTodo(p graphql.ResolveParams) (interface{}, error) {
+	return c.todoService.UpdateData(p.Context, p.Args["id"].(int), &todo.UpdateRequest{
+		Title:       p.Args["title"].(string),
+		Description: p.Args["description"].(string),
+		IsDone:      boolArg(p.Args, "isDone"),
+		IsFavorite:  boolArg(p.Args, "isFavorite"),
+	})
+}
+
+func (c *resolver) markDone(p graphql.ResolveParams) (interface{}, error) {
+	id := p.Args["id"].(int)
+	if err := c.todoService.MarkAsDone(p.Context, id, &todo.DoneRequest{IsDone: boolArg(p.Args, "isDone")}); err != nil {
+		return nil, err
+	}
+
+	return c.todoService.GetByID(p.Context, id)
+}
+
+func (c *resolver) markFavorite(p graphql.ResolveParams) (interface{}, error) {
+	id := p.Args["id"].(int)
+	if err := c.todoService.MarkAsFavorite(p.Context, id, &todo.FavoriteRequest{IsFavorite: boolArg(p.Args, "isFavorite")}); err != nil {
+		return nil, err
+	}
+
+	return c.todoService.GetByID(p.Context, id)
+}
+
+func (c *resolver) deleteTodo(p graphql.ResolveParams) (interface{}, error) {
+	if err := c.todoService.DeleteByID(p.Context, p.Args["id"].(int)); err != nil {
+		return nil, err
+	}
+
+	return true, nil
+}
+
+// todoChanged feeds broker events into the subscription until the request
+// context is done.
+func (c *resolver) todoChanged(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(int)
+
+	events := c.broker.Subscribe(64)
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+		defer c.broker.Unsubscribe(events)
+
+		for {
+			select {
+			case <-p.Context.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+
+				if id > 0 && int(e.Todo.ID) != id {
+					continue
+				}
+
+				select {
+				case ch <- e:
+				case <-p.Context.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (c *resolver) prime(ctx context.Context, data []todo.ViewResponse) []*todo.ViewResponse {
+	l := loaderFrom(ctx, c.todoService)
+
+	result := make([]*todo.ViewResponse, 0, len(data))
+	for i := range data {
+		l.prime(&data[i])
+		result = append(result, &data[i])
+	}
+
+	return result
+}
+
+// boolArg renders an optional boolean argument the way the service expects
+// it: "true", "false" or empty when the argument was not given.
+func boolArg(args map[string]interface{}, name string) string {
+	v, ok := args[name].(bool)
+	if !ok {
+		return ""
+	}
+
+	return strconv.FormatBool(v)
+}
@@ -0,0 +1,203 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/ardiantirta/todo-crud/services/todo/delivery/grpc/todopb"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type TodoServer struct {
+	TodoService service.Service
+	broker      *event.Broker
+}
+
+func NewTodoServer(s *grpc.Server, todoService service.Service, broker *event.Broker) {
+	server := &TodoServer{
+		TodoService: todoService,
+		broker:      broker,
+	}
+
+	todopb.RegisterTodoServiceServer(s, server)
+}
+
+func (c *TodoServer) Create(ctx context.Context, req *todopb.CreateRequest) (*todopb.Todo, error) {
+	resp, err := c.TodoService.Create(ctx, &todo.CreateRequest{
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	view := todo.ViewResponse(*resp)
+	return toProto(&view), nil
+}
+
+func (c *TodoServer) GetByID(ctx context.Context, req *todopb.GetByIDRequest) (*todopb.Todo, error) {
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id should be a positive number")
+	}
+
+	resp, err := c.TodoService.GetByID(ctx, int(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(resp), nil
+}
+
+func (c *TodoServer) GetByTitle(ctx context.Context, req *todopb.GetByTitleRequest) (*todopb.TodoList, error) {
+	params := map[string]interface{}{
+		"title": req.Title,
+	}
+
+	resp, err := c.TodoService.GetByTitle(ctx, params)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoList(resp), nil
+}
+
+func (c *TodoServer) GetAll(ctx context.Context, req *todopb.GetAllRequest) (*todopb.TodoList, error) {
+	params := map[string]interface{}{
+		"is_done":     req.IsDone,
+		"is_favorite": req.IsFavorite,
+	}
+
+	resp, err := c.TodoService.GetAll(ctx, params)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoList(resp), nil
+}
+
+func (c *TodoServer) UpdateData(ctx context.Context, req *todopb.UpdateRequest) (*todopb.Todo, error) {
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id should be a positive number")
+	}
+
+	resp, err := c.TodoService.UpdateData(ctx, int(req.Id), &todo.UpdateRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		IsDone:      req.IsDone,
+		IsFavorite:  req.IsFavorite,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(resp), nil
+}
+
+func (c *TodoServer) MarkAsDone(ctx context.Context, req *todopb.DoneRequest) (*empty.Empty, error) {
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id should be a positive number")
+	}
+
+	if err := c.TodoService.MarkAsDone(ctx, int(req.Id), &todo.DoneRequest{IsDone: req.IsDone}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (c *TodoServer) MarkAsFavorite(ctx context.Context, req *todopb.FavoriteRequest) (*empty.Empty, error) {
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id should be a positive number")
+	}
+
+	if err := c.TodoService.MarkAsFavorite(ctx, int(req.Id), &todo.FavoriteRequest{IsFavorite: req.IsFavorite}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (c *TodoServer) DeleteByID(ctx context.Context, req *todopb.DeleteByIDRequest) (*empty.Empty, error) {
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id should be a positive number")
+	}
+
+	if err := c.TodoService.DeleteByID(ctx, int(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (c *TodoServer) WatchTodos(req *todopb.WatchTodosRequest, stream todopb.TodoService_WatchTodosServer) error {
+	events := c.broker.Subscribe(64)
+	defer c.broker.Unsubscribe(events)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream closed")
+			}
+
+			if req.Id > 0 && uint64(e.Todo.ID) != uint64(req.Id) {
+				continue
+			}
+
+			if err := stream.Send(&todopb.TodoEvent{Type: string(e.Type), Todo: toProto(&e.Todo)}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus maps service errors onto gRPC status codes: repository errors
+// are not found, deadline exceeded on a timeout, canceled or internal,
+// anything else is a validation error.
+func toStatus(err error) error {
+	switch err {
+	case repository.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case repository.ErrTimeout:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case repository.ErrCanceled:
+		return status.Error(codes.Canceled, err.Error())
+	case repository.ErrCreate, repository.ErrSave, repository.ErrGet, repository.ErrGetChanged, repository.ErrDelete:
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func toProto(data *todo.ViewResponse) *todopb.Todo {
+	createdAt, _ := ptypes.TimestampProto(data.CreatedAt)
+	updatedAt, _ := ptypes.TimestampProto(data.UpdatedAt)
+
+	return &todopb.Todo{
+		Id:          uint64(data.ID),
+		Title:       data.Title,
+		Description: data.Description,
+		IsDone:      data.IsDone,
+		IsFavorite:  data.IsFavorite,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+	}
+}
+
+func toProtoList(data []todo.ViewResponse) *todopb.TodoList {
+	todos := make([]*todopb.Todo, 0, len(data))
+	for i := range data {
+		todos = append(todos, toProto(&data[i]))
+	}
+
+	return &todopb.TodoList{Todos: todos}
+}
@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/delivery/grpc/todopb"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/jinzhu/gorm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeService fails GetByID with the error registered for the id.
+type fakeService struct {
+	service.Service
+	errs map[int]error
+}
+
+func (c *fakeService) GetByID(ctx context.Context, id int) (*todo.ViewResponse, error) {
+	if err, ok := c.errs[id]; ok {
+		return nil, err
+	}
+
+	return &todo.ViewResponse{Title: "Buy milk"}, nil
+}
+
+// dial serves a TodoServer over an in-memory listener and returns a client
+// for it. The returned func stops the server.
+func dial(t *testing.T, todoService service.Service, broker *event.Broker) (todopb.TodoServiceClient, func()) {
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	NewTodoServer(s, todoService, broker)
+	go s.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		s.Stop()
+		t.Fatal(err)
+	}
+
+	return todopb.NewTodoServiceClient(conn), func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	todoService := &fakeService{errs: map[int]error{
+		1: repository.ErrNotFound,
+		2: repository.ErrTimeout,
+		3: repository.ErrCanceled,
+		4: repository.ErrGet,
+		5: errors.New("title: harus diisi"),
+	}}
+	client, stop := dial(t, todoService, event.NewBroker())
+	defer stop()
+
+	tests := []struct {
+		id   int64
+		code codes.Code
+	}{
+		{0, codes.InvalidArgument},
+		{1, codes.NotFound},
+		{2, codes.DeadlineExceeded},
+		{3, codes.Canceled},
+		{4, codes.Internal},
+		{5, codes.InvalidArgument},
+		{6, codes.OK},
+	}
+
+	for _, tt := range tests {
+		_, err := client.GetByID(context.Background(), &todopb.GetByIDRequest{Id: tt.id})
+		if got := status.Code(err); got != tt.code {
+			t.Errorf("GetByID(%d): code = %s, want %s (%v)", tt.id, got, tt.code, err)
+		}
+	}
+}
+
+func TestWatchTodos(t *testing.T) {
+	broker := event.NewBroker()
+	client, stop := dial(t, &fakeService{}, broker)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchTodos(ctx, &todopb.WatchTodosRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server subscribes once the stream has started, so keep
+	// publishing until the event gets through. The update to todo 2 must
+	// be filtered out.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			broker.Publish(event.Event{Type: event.Updated, Todo: todo.ViewResponse{Model: gorm.Model{ID: 2}, Title: "Call the plumber"}})
+			broker.Publish(event.Event{Type: event.Created, Todo: todo.ViewResponse{Model: gorm.Model{ID: 1}, Title: "Buy milk"}})
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	e, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != string(event.Created) || e.Todo.GetId() != 1 || e.Todo.GetTitle() != "Buy milk" {
+		t.Errorf("event = %v, want created todo 1", e)
+	}
+}
+
+func TestWatchTodosEndsWhenTheBrokerCloses(t *testing.T) {
+	broker := event.NewBroker()
+	broker.Close()
+	client, stop := dial(t, &fakeService{}, broker)
+	defer stop()
+
+	stream, err := client.WatchTodos(context.Background(), &todopb.WatchTodosRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Recv: err = %v, want Unavailable", err)
+	}
+}
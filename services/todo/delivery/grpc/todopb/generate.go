@@ -0,0 +1,3 @@
+package todopb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. todo.proto
@@ -0,0 +1,1076 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: todo.proto
+
+package todopb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Todo struct {
+	Id                   uint64               `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title                string               `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description          string               `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	IsDone               bool                 `protobuf:"varint,4,opt,name=is_done,json=isDone,proto3" json:"is_done,omitempty"`
+	IsFavorite           bool                 `protobuf:"varint,5,opt,name=is_favorite,json=isFavorite,proto3" json:"is_favorite,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Todo) Reset()         { *m = Todo{} }
+func (m *Todo) String() string { return proto.CompactTextString(m) }
+func (*Todo) ProtoMessage()    {}
+func (*Todo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{0}
+}
+
+func (m *Todo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Todo.Unmarshal(m, b)
+}
+func (m *Todo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Todo.Marshal(b, m, deterministic)
+}
+func (m *Todo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Todo.Merge(m, src)
+}
+func (m *Todo) XXX_Size() int {
+	return xxx_messageInfo_Todo.Size(m)
+}
+func (m *Todo) XXX_DiscardUnknown() {
+	xxx_messageInfo_Todo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Todo proto.InternalMessageInfo
+
+func (m *Todo) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Todo) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *Todo) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Todo) GetIsDone() bool {
+	if m != nil {
+		return m.IsDone
+	}
+	return false
+}
+
+func (m *Todo) GetIsFavorite() bool {
+	if m != nil {
+		return m.IsFavorite
+	}
+	return false
+}
+
+func (m *Todo) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *Todo) GetUpdatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+type TodoList struct {
+	Todos                []*Todo  `protobuf:"bytes,1,rep,name=todos,proto3" json:"todos,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TodoList) Reset()         { *m = TodoList{} }
+func (m *TodoList) String() string { return proto.CompactTextString(m) }
+func (*TodoList) ProtoMessage()    {}
+func (*TodoList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{1}
+}
+
+func (m *TodoList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoList.Unmarshal(m, b)
+}
+func (m *TodoList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoList.Marshal(b, m, deterministic)
+}
+func (m *TodoList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoList.Merge(m, src)
+}
+func (m *TodoList) XXX_Size() int {
+	return xxx_messageInfo_TodoList.Size(m)
+}
+func (m *TodoList) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TodoList proto.InternalMessageInfo
+
+func (m *TodoList) GetTodos() []*Todo {
+	if m != nil {
+		return m.Todos
+	}
+	return nil
+}
+
+type CreateRequest struct {
+	Title                string   `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description          string   `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateRequest) Reset()         { *m = CreateRequest{} }
+func (m *CreateRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateRequest) ProtoMessage()    {}
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{2}
+}
+
+func (m *CreateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRequest.Unmarshal(m, b)
+}
+func (m *CreateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRequest.Merge(m, src)
+}
+func (m *CreateRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateRequest.Size(m)
+}
+func (m *CreateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRequest proto.InternalMessageInfo
+
+func (m *CreateRequest) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type GetByIDRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetByIDRequest) Reset()         { *m = GetByIDRequest{} }
+func (m *GetByIDRequest) String() string { return proto.CompactTextString(m) }
+func (*GetByIDRequest) ProtoMessage()    {}
+func (*GetByIDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{3}
+}
+
+func (m *GetByIDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetByIDRequest.Unmarshal(m, b)
+}
+func (m *GetByIDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetByIDRequest.Marshal(b, m, deterministic)
+}
+func (m *GetByIDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetByIDRequest.Merge(m, src)
+}
+func (m *GetByIDRequest) XXX_Size() int {
+	return xxx_messageInfo_GetByIDRequest.Size(m)
+}
+func (m *GetByIDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetByIDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetByIDRequest proto.InternalMessageInfo
+
+func (m *GetByIDRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type GetByTitleRequest struct {
+	Title                string   `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetByTitleRequest) Reset()         { *m = GetByTitleRequest{} }
+func (m *GetByTitleRequest) String() string { return proto.CompactTextString(m) }
+func (*GetByTitleRequest) ProtoMessage()    {}
+func (*GetByTitleRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{4}
+}
+
+func (m *GetByTitleRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetByTitleRequest.Unmarshal(m, b)
+}
+func (m *GetByTitleRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetByTitleRequest.Marshal(b, m, deterministic)
+}
+func (m *GetByTitleRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetByTitleRequest.Merge(m, src)
+}
+func (m *GetByTitleRequest) XXX_Size() int {
+	return xxx_messageInfo_GetByTitleRequest.Size(m)
+}
+func (m *GetByTitleRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetByTitleRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetByTitleRequest proto.InternalMessageInfo
+
+func (m *GetByTitleRequest) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+type GetAllRequest struct {
+	IsDone               string   `protobuf:"bytes,1,opt,name=is_done,json=isDone,proto3" json:"is_done,omitempty"`
+	IsFavorite           string   `protobuf:"bytes,2,opt,name=is_favorite,json=isFavorite,proto3" json:"is_favorite,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetAllRequest) Reset()         { *m = GetAllRequest{} }
+func (m *GetAllRequest) String() string { return proto.CompactTextString(m) }
+func (*GetAllRequest) ProtoMessage()    {}
+func (*GetAllRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{5}
+}
+
+func (m *GetAllRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAllRequest.Unmarshal(m, b)
+}
+func (m *GetAllRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAllRequest.Marshal(b, m, deterministic)
+}
+func (m *GetAllRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAllRequest.Merge(m, src)
+}
+func (m *GetAllRequest) XXX_Size() int {
+	return xxx_messageInfo_GetAllRequest.Size(m)
+}
+func (m *GetAllRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAllRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAllRequest proto.InternalMessageInfo
+
+func (m *GetAllRequest) GetIsDone() string {
+	if m != nil {
+		return m.IsDone
+	}
+	return ""
+}
+
+func (m *GetAllRequest) GetIsFavorite() string {
+	if m != nil {
+		return m.IsFavorite
+	}
+	return ""
+}
+
+type UpdateRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title                string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description          string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	IsDone               string   `protobuf:"bytes,4,opt,name=is_done,json=isDone,proto3" json:"is_done,omitempty"`
+	IsFavorite           string   `protobuf:"bytes,5,opt,name=is_favorite,json=isFavorite,proto3" json:"is_favorite,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
+func (m *UpdateRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateRequest) ProtoMessage()    {}
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{6}
+}
+
+func (m *UpdateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateRequest.Unmarshal(m, b)
+}
+func (m *UpdateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateRequest.Merge(m, src)
+}
+func (m *UpdateRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateRequest.Size(m)
+}
+func (m *UpdateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateRequest proto.InternalMessageInfo
+
+func (m *UpdateRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *UpdateRequest) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetIsDone() string {
+	if m != nil {
+		return m.IsDone
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetIsFavorite() string {
+	if m != nil {
+		return m.IsFavorite
+	}
+	return ""
+}
+
+type DoneRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	IsDone               string   `protobuf:"bytes,2,opt,name=is_done,json=isDone,proto3" json:"is_done,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DoneRequest) Reset()         { *m = DoneRequest{} }
+func (m *DoneRequest) String() string { return proto.CompactTextString(m) }
+func (*DoneRequest) ProtoMessage()    {}
+func (*DoneRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{7}
+}
+
+func (m *DoneRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DoneRequest.Unmarshal(m, b)
+}
+func (m *DoneRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DoneRequest.Marshal(b, m, deterministic)
+}
+func (m *DoneRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DoneRequest.Merge(m, src)
+}
+func (m *DoneRequest) XXX_Size() int {
+	return xxx_messageInfo_DoneRequest.Size(m)
+}
+func (m *DoneRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DoneRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DoneRequest proto.InternalMessageInfo
+
+func (m *DoneRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *DoneRequest) GetIsDone() string {
+	if m != nil {
+		return m.IsDone
+	}
+	return ""
+}
+
+type FavoriteRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	IsFavorite           string   `protobuf:"bytes,2,opt,name=is_favorite,json=isFavorite,proto3" json:"is_favorite,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FavoriteRequest) Reset()         { *m = FavoriteRequest{} }
+func (m *FavoriteRequest) String() string { return proto.CompactTextString(m) }
+func (*FavoriteRequest) ProtoMessage()    {}
+func (*FavoriteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{8}
+}
+
+func (m *FavoriteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FavoriteRequest.Unmarshal(m, b)
+}
+func (m *FavoriteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FavoriteRequest.Marshal(b, m, deterministic)
+}
+func (m *FavoriteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FavoriteRequest.Merge(m, src)
+}
+func (m *FavoriteRequest) XXX_Size() int {
+	return xxx_messageInfo_FavoriteRequest.Size(m)
+}
+func (m *FavoriteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FavoriteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FavoriteRequest proto.InternalMessageInfo
+
+func (m *FavoriteRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *FavoriteRequest) GetIsFavorite() string {
+	if m != nil {
+		return m.IsFavorite
+	}
+	return ""
+}
+
+type DeleteByIDRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteByIDRequest) Reset()         { *m = DeleteByIDRequest{} }
+func (m *DeleteByIDRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteByIDRequest) ProtoMessage()    {}
+func (*DeleteByIDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{9}
+}
+
+func (m *DeleteByIDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteByIDRequest.Unmarshal(m, b)
+}
+func (m *DeleteByIDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteByIDRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteByIDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteByIDRequest.Merge(m, src)
+}
+func (m *DeleteByIDRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteByIDRequest.Size(m)
+}
+func (m *DeleteByIDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteByIDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteByIDRequest proto.InternalMessageInfo
+
+func (m *DeleteByIDRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+// WatchTodosRequest streams every change, or only the changes of one todo
+// when id is set.
+type WatchTodosRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchTodosRequest) Reset()         { *m = WatchTodosRequest{} }
+func (m *WatchTodosRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchTodosRequest) ProtoMessage()    {}
+func (*WatchTodosRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{10}
+}
+
+func (m *WatchTodosRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchTodosRequest.Unmarshal(m, b)
+}
+func (m *WatchTodosRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchTodosRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchTodosRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchTodosRequest.Merge(m, src)
+}
+func (m *WatchTodosRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchTodosRequest.Size(m)
+}
+func (m *WatchTodosRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchTodosRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchTodosRequest proto.InternalMessageInfo
+
+func (m *WatchTodosRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type TodoEvent struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Todo                 *Todo    `protobuf:"bytes,2,opt,name=todo,proto3" json:"todo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TodoEvent) Reset()         { *m = TodoEvent{} }
+func (m *TodoEvent) String() string { return proto.CompactTextString(m) }
+func (*TodoEvent) ProtoMessage()    {}
+func (*TodoEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{11}
+}
+
+func (m *TodoEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoEvent.Unmarshal(m, b)
+}
+func (m *TodoEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoEvent.Marshal(b, m, deterministic)
+}
+func (m *TodoEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoEvent.Merge(m, src)
+}
+func (m *TodoEvent) XXX_Size() int {
+	return xxx_messageInfo_TodoEvent.Size(m)
+}
+func (m *TodoEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TodoEvent proto.InternalMessageInfo
+
+func (m *TodoEvent) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *TodoEvent) GetTodo() *Todo {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Todo)(nil), "todo.v1.Todo")
+	proto.RegisterType((*TodoList)(nil), "todo.v1.TodoList")
+	proto.RegisterType((*CreateRequest)(nil), "todo.v1.CreateRequest")
+	proto.RegisterType((*GetByIDRequest)(nil), "todo.v1.GetByIDRequest")
+	proto.RegisterType((*GetByTitleRequest)(nil), "todo.v1.GetByTitleRequest")
+	proto.RegisterType((*GetAllRequest)(nil), "todo.v1.GetAllRequest")
+	proto.RegisterType((*UpdateRequest)(nil), "todo.v1.UpdateRequest")
+	proto.RegisterType((*DoneRequest)(nil), "todo.v1.DoneRequest")
+	proto.RegisterType((*FavoriteRequest)(nil), "todo.v1.FavoriteRequest")
+	proto.RegisterType((*DeleteByIDRequest)(nil), "todo.v1.DeleteByIDRequest")
+	proto.RegisterType((*WatchTodosRequest)(nil), "todo.v1.WatchTodosRequest")
+	proto.RegisterType((*TodoEvent)(nil), "todo.v1.TodoEvent")
+}
+
+func init() {
+	proto.RegisterFile("todo.proto", fileDescriptor_0e4b95d0c4e09639)
+}
+
+var fileDescriptor_0e4b95d0c4e09639 = []byte{
+	// 629 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x94, 0x4f, 0x6f, 0xda, 0x4c,
+	0x10, 0xc6, 0x65, 0x42, 0x20, 0x8c, 0x45, 0x5e, 0xb1, 0x8a, 0x12, 0xcb, 0xef, 0x21, 0xae, 0x73,
+	0xa1, 0x87, 0xda, 0x4d, 0x22, 0x55, 0x6a, 0x23, 0x55, 0x85, 0x92, 0x46, 0xa9, 0xd2, 0x8b, 0x9b,
+	0xaa, 0x52, 0x2f, 0xc8, 0x78, 0x37, 0x64, 0x55, 0xc3, 0xba, 0xde, 0x01, 0x89, 0x8f, 0xd1, 0x6b,
+	0x3f, 0x6c, 0x55, 0x79, 0x17, 0x83, 0x0d, 0x35, 0xb9, 0xf4, 0x84, 0xbd, 0xf3, 0x3c, 0xe3, 0xf9,
+	0xf3, 0x63, 0x01, 0x50, 0x50, 0xe1, 0x25, 0xa9, 0x40, 0x41, 0x9a, 0xea, 0x79, 0x7e, 0x6e, 0xff,
+	0x3f, 0x16, 0x62, 0x1c, 0x33, 0x5f, 0x1d, 0x8f, 0x66, 0x0f, 0x3e, 0x9b, 0x24, 0xb8, 0xd0, 0x2a,
+	0xfb, 0x74, 0x33, 0x88, 0x7c, 0xc2, 0x24, 0x86, 0x93, 0x44, 0x0b, 0xdc, 0xdf, 0x06, 0xd4, 0xef,
+	0x05, 0x15, 0xe4, 0x10, 0x6a, 0x9c, 0x5a, 0x86, 0x63, 0x74, 0xeb, 0x41, 0x8d, 0x53, 0x72, 0x04,
+	0xfb, 0xc8, 0x31, 0x66, 0x56, 0xcd, 0x31, 0xba, 0xad, 0x40, 0xbf, 0x10, 0x07, 0x4c, 0xca, 0x64,
+	0x94, 0xf2, 0x04, 0xb9, 0x98, 0x5a, 0x7b, 0x2a, 0x56, 0x3c, 0x22, 0x27, 0xd0, 0xe4, 0x72, 0x48,
+	0xc5, 0x94, 0x59, 0x75, 0xc7, 0xe8, 0x1e, 0x04, 0x0d, 0x2e, 0x07, 0x62, 0xca, 0xc8, 0x29, 0x98,
+	0x5c, 0x0e, 0x1f, 0xc2, 0xb9, 0x48, 0x39, 0x32, 0x6b, 0x5f, 0x05, 0x81, 0xcb, 0x0f, 0xcb, 0x13,
+	0xf2, 0x1a, 0x20, 0x4a, 0x59, 0x88, 0x8c, 0x0e, 0x43, 0xb4, 0x1a, 0x8e, 0xd1, 0x35, 0x2f, 0x6c,
+	0x4f, 0x37, 0xe0, 0xe5, 0x0d, 0x78, 0xf7, 0x79, 0x03, 0x41, 0x6b, 0xa9, 0xee, 0x61, 0x66, 0x9d,
+	0x25, 0x34, 0xb7, 0x36, 0x9f, 0xb6, 0x2e, 0xd5, 0x3d, 0x74, 0x7d, 0x38, 0xc8, 0xfa, 0xbf, 0xe3,
+	0x12, 0xc9, 0x19, 0xec, 0xa3, 0xa0, 0x42, 0x5a, 0x86, 0xb3, 0xd7, 0x35, 0x2f, 0xda, 0xde, 0x72,
+	0xc6, 0x5e, 0xa6, 0x08, 0x74, 0xcc, 0xbd, 0x81, 0xf6, 0x7b, 0xf5, 0xe1, 0x80, 0xfd, 0x98, 0x31,
+	0x89, 0xeb, 0x49, 0x19, 0x3b, 0x26, 0x55, 0xdb, 0x9a, 0x94, 0xeb, 0xc0, 0xe1, 0x0d, 0xc3, 0xfe,
+	0xe2, 0x76, 0x90, 0x67, 0x5a, 0xef, 0x60, 0x2f, 0xdb, 0x81, 0xfb, 0x1c, 0x3a, 0x4a, 0x71, 0x9f,
+	0x65, 0xdc, 0xf9, 0x39, 0xf7, 0x16, 0xda, 0x37, 0x0c, 0x7b, 0x71, 0x9c, 0xcb, 0x0a, 0x7b, 0xd0,
+	0xc2, 0x8a, 0x3d, 0xe8, 0xc2, 0x0a, 0x7b, 0x70, 0x7f, 0x1a, 0xd0, 0xfe, 0xa2, 0xe6, 0x53, 0x51,
+	0xd7, 0xbf, 0x62, 0xa3, 0xb5, 0x8b, 0x8d, 0x72, 0x4d, 0xaf, 0xc0, 0xcc, 0x84, 0x55, 0x05, 0x15,
+	0x12, 0xd7, 0x8a, 0x89, 0xdd, 0x3e, 0xfc, 0x97, 0xe7, 0xa8, 0xf2, 0x3e, 0x39, 0x8f, 0x33, 0xe8,
+	0x0c, 0x58, 0xcc, 0x90, 0xed, 0x5a, 0xd5, 0x19, 0x74, 0xbe, 0x86, 0x18, 0x3d, 0x66, 0xa4, 0xc8,
+	0x2a, 0x51, 0x1f, 0x5a, 0x59, 0xfc, 0x7a, 0xce, 0xa6, 0x48, 0x08, 0xd4, 0x71, 0x91, 0xe4, 0xdb,
+	0x51, 0xcf, 0xe4, 0x19, 0xd4, 0x51, 0x50, 0xa1, 0x8a, 0xd8, 0xe2, 0x4f, 0x85, 0x2e, 0x7e, 0xd5,
+	0xc1, 0xcc, 0x5e, 0x3f, 0xb3, 0x74, 0xce, 0x23, 0x46, 0x7c, 0x68, 0x68, 0x1c, 0xc9, 0xf1, 0x4a,
+	0x5e, 0xe2, 0xd3, 0x2e, 0xa7, 0x21, 0xe7, 0xd0, 0x5c, 0x62, 0x47, 0x4e, 0x56, 0x91, 0x32, 0x88,
+	0x9b, 0x96, 0x2b, 0x80, 0x35, 0x87, 0xc4, 0x2e, 0xbb, 0x8a, 0x70, 0xda, 0x9d, 0x92, 0x51, 0xfd,
+	0xa9, 0x2e, 0xa1, 0xa1, 0xc9, 0x2c, 0x14, 0x58, 0x42, 0xf5, 0xef, 0x26, 0xd0, 0x08, 0x0e, 0x42,
+	0x0c, 0x0b, 0xc6, 0x12, 0x97, 0x9b, 0x65, 0xbe, 0x01, 0xf8, 0x14, 0xa6, 0xdf, 0x7b, 0x9a, 0xa9,
+	0xa3, 0x55, 0xb0, 0x40, 0x8e, 0x7d, 0xbc, 0x75, 0x2b, 0x5c, 0x67, 0xd7, 0x25, 0xe9, 0xc3, 0xa1,
+	0xf6, 0xae, 0xae, 0x23, 0x6b, 0xe5, 0xdf, 0x20, 0xa8, 0x32, 0xc7, 0x3b, 0x80, 0x35, 0x28, 0x85,
+	0x31, 0x6d, 0xd1, 0x53, 0x99, 0xe1, 0x2d, 0xc0, 0x9a, 0xa2, 0x42, 0x86, 0x2d, 0xb4, 0x6c, 0x52,
+	0x6a, 0x5d, 0x11, 0xf5, 0xd2, 0xe8, 0xdf, 0x7d, 0xfb, 0x38, 0xe6, 0xf8, 0x38, 0x1b, 0x79, 0x91,
+	0x98, 0xf8, 0x61, 0x4a, 0x79, 0x38, 0x45, 0x9e, 0x62, 0xe8, 0x67, 0xea, 0x17, 0x51, 0x3a, 0xa3,
+	0xbe, 0xd4, 0xe0, 0x48, 0x75, 0xe4, 0x53, 0x16, 0xf3, 0x39, 0x4b, 0x17, 0xfe, 0x38, 0x4d, 0x22,
+	0x75, 0x94, 0x8c, 0xae, 0xf4, 0xcf, 0xa8, 0xa1, 0xaa, 0xbb, 0xfc, 0x33, 0x00, 0xfb, 0x68, 0xf9,
+	0x63, 0x77, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// TodoServiceClient is the client API for TodoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TodoServiceClient interface {
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Todo, error)
+	GetByID(ctx context.Context, in *GetByIDRequest, opts ...grpc.CallOption) (*Todo, error)
+	GetByTitle(ctx context.Context, in *GetByTitleRequest, opts ...grpc.CallOption) (*TodoList, error)
+	GetAll(ctx context.Context, in *GetAllRequest, opts ...grpc.CallOption) (*TodoList, error)
+	UpdateData(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Todo, error)
+	MarkAsDone(ctx context.Context, in *DoneRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	MarkAsFavorite(ctx context.Context, in *FavoriteRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	DeleteByID(ctx context.Context, in *DeleteByIDRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	WatchTodos(ctx context.Context, in *WatchTodosRequest, opts ...grpc.CallOption) (TodoService_WatchTodosClient, error)
+}
+
+type todoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTodoServiceClient(cc grpc.ClientConnInterface) TodoServiceClient {
+	return &todoServiceClient{cc}
+}
+
+func (c *todoServiceClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) GetByID(ctx context.Context, in *GetByIDRequest, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/GetByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) GetByTitle(ctx context.Context, in *GetByTitleRequest, opts ...grpc.CallOption) (*TodoList, error) {
+	out := new(TodoList)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/GetByTitle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) GetAll(ctx context.Context, in *GetAllRequest, opts ...grpc.CallOption) (*TodoList, error) {
+	out := new(TodoList)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/GetAll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) UpdateData(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/UpdateData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) MarkAsDone(ctx context.Context, in *DoneRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/MarkAsDone", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) MarkAsFavorite(ctx context.Context, in *FavoriteRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/MarkAsFavorite", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) DeleteByID(ctx context.Context, in *DeleteByIDRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/todo.v1.TodoService/DeleteByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) WatchTodos(ctx context.Context, in *WatchTodosRequest, opts ...grpc.CallOption) (TodoService_WatchTodosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TodoService_serviceDesc.Streams[0], "/todo.v1.TodoService/WatchTodos", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoServiceWatchTodosClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TodoService_WatchTodosClient interface {
+	Recv() (*TodoEvent, error)
+	grpc.ClientStream
+}
+
+type todoServiceWatchTodosClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoServiceWatchTodosClient) Recv() (*TodoEvent, error) {
+	m := new(TodoEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TodoServiceServer is the server API for TodoService service.
+type TodoServiceServer interface {
+	Create(context.Context, *CreateRequest) (*Todo, error)
+	GetByID(context.Context, *GetByIDRequest) (*Todo, error)
+	GetByTitle(context.Context, *GetByTitleRequest) (*TodoList, error)
+	GetAll(context.Context, *GetAllRequest) (*TodoList, error)
+	UpdateData(context.Context, *UpdateRequest) (*Todo, error)
+	MarkAsDone(context.Context, *DoneRequest) (*empty.Empty, error)
+	MarkAsFavorite(context.Context, *FavoriteRequest) (*empty.Empty, error)
+	DeleteByID(context.Context, *DeleteByIDRequest) (*empty.Empty, error)
+	WatchTodos(*WatchTodosRequest, TodoService_WatchTodosServer) error
+}
+
+// UnimplementedTodoServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedTodoServiceServer struct {
+}
+
+func (*UnimplementedTodoServiceServer) Create(ctx context.Context, req *CreateRequest) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (*UnimplementedTodoServiceServer) GetByID(ctx context.Context, req *GetByIDRequest) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByID not implemented")
+}
+func (*UnimplementedTodoServiceServer) GetByTitle(ctx context.Context, req *GetByTitleRequest) (*TodoList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByTitle not implemented")
+}
+func (*UnimplementedTodoServiceServer) GetAll(ctx context.Context, req *GetAllRequest) (*TodoList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAll not implemented")
+}
+func (*UnimplementedTodoServiceServer) UpdateData(ctx context.Context, req *UpdateRequest) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateData not implemented")
+}
+func (*UnimplementedTodoServiceServer) MarkAsDone(ctx context.Context, req *DoneRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkAsDone not implemented")
+}
+func (*UnimplementedTodoServiceServer) MarkAsFavorite(ctx context.Context, req *FavoriteRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkAsFavorite not implemented")
+}
+func (*UnimplementedTodoServiceServer) DeleteByID(ctx context.Context, req *DeleteByIDRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteByID not implemented")
+}
+func (*UnimplementedTodoServiceServer) WatchTodos(req *WatchTodosRequest, srv TodoService_WatchTodosServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTodos not implemented")
+}
+
+func RegisterTodoServiceServer(s *grpc.Server, srv TodoServiceServer) {
+	s.RegisterService(&_TodoService_serviceDesc, srv)
+}
+
+func _TodoService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_GetByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).GetByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/GetByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).GetByID(ctx, req.(*GetByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_GetByTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByTitleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).GetByTitle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/GetByTitle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).GetByTitle(ctx, req.(*GetByTitleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_GetAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).GetAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/GetAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).GetAll(ctx, req.(*GetAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_UpdateData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).UpdateData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/UpdateData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).UpdateData(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_MarkAsDone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).MarkAsDone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/MarkAsDone",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).MarkAsDone(ctx, req.(*DoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_MarkAsFavorite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FavoriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).MarkAsFavorite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/MarkAsFavorite",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).MarkAsFavorite(ctx, req.(*FavoriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_DeleteByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).DeleteByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.v1.TodoService/DeleteByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).DeleteByID(ctx, req.(*DeleteByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_WatchTodos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTodosRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoServiceServer).WatchTodos(m, &todoServiceWatchTodosServer{stream})
+}
+
+type TodoService_WatchTodosServer interface {
+	Send(*TodoEvent) error
+	grpc.ServerStream
+}
+
+type todoServiceWatchTodosServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoServiceWatchTodosServer) Send(m *TodoEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _TodoService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "todo.v1.TodoService",
+	HandlerType: (*TodoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _TodoService_Create_Handler,
+		},
+		{
+			MethodName: "GetByID",
+			Handler:    _TodoService_GetByID_Handler,
+		},
+		{
+			MethodName: "GetByTitle",
+			Handler:    _TodoService_GetByTitle_Handler,
+		},
+		{
+			MethodName: "GetAll",
+			Handler:    _TodoService_GetAll_Handler,
+		},
+		{
+			MethodName: "UpdateData",
+			Handler:    _TodoService_UpdateData_Handler,
+		},
+		{
+			MethodName: "MarkAsDone",
+			Handler:    _TodoService_MarkAsDone_Handler,
+		},
+		{
+			MethodName: "MarkAsFavorite",
+			Handler:    _TodoService_MarkAsFavorite_Handler,
+		},
+		{
+			MethodName: "DeleteByID",
+			Handler:    _TodoService_DeleteByID_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTodos",
+			Handler:       _TodoService_WatchTodos_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "todo.proto",
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/logging"
+	"github.com/ardiantirta/todo-crud/services/todo/codec"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/gorilla/mux"
+)
+
+// CalendarHandler serves the iCalendar feed calendar apps subscribe to.
+// The feed has no other authentication, so the token in its URL is the
+// secret; an empty token disables the feed.
+type CalendarHandler struct {
+	TransferService service.TransferService
+	Token           string
+}
+
+func NewCalendarHandler(r *mux.Router, transferService service.TransferService, token string) {
+	handler := &CalendarHandler{
+		TransferService: transferService,
+		Token:           token,
+	}
+
+	r.Handle("/calendar/{token}.ics", http.HandlerFunc(handler.Feed)).Methods(http.MethodGet)
+}
+
+func (c *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+	if c.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(c.Token)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	out := &trackingWriter{w: w}
+	enc, _ := codec.NewEncoder(codec.FormatICS, out)
+
+	w.Header().Set("Content-Type", codec.ContentType(codec.FormatICS))
+	w.Header().Set("Cache-Control", "no-cache")
+
+	params := map[string]interface{}{
+		"is_done":     "",
+		"is_favorite": "",
+	}
+
+	if err := c.TransferService.Export(r.Context(), params, enc); err != nil {
+		logging.FromContext(r.Context()).WithError(err).Error("export failed")
+		if !out.written {
+			http.Error(w, "failed to render calendar", http.StatusInternalServerError)
+		}
+	}
+}
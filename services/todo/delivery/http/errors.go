@@ -0,0 +1,24 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+)
+
+// errorStatus is the status for a service error: 404 for a missing todo,
+// 503 when the database did not answer in time, 499 when the client went
+// away, and fallback for anything else.
+func errorStatus(err error, fallback int) int {
+	switch err {
+	case repository.ErrNotFound:
+		return http.StatusNotFound
+	case repository.ErrTimeout:
+		return http.StatusServiceUnavailable
+	case repository.ErrCanceled:
+		return response.StatusClientClosedRequest
+	}
+
+	return fallback
+}
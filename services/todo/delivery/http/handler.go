@@ -7,13 +7,14 @@ import (
 	"github.com/ardiantirta/todo-crud/common/message"
 	"github.com/ardiantirta/todo-crud/services/todo/service"
 	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"net/http"
-	"os"
 	"strconv"
 )
 
+// MaxPageSize is the largest limit GET /todo accepts.
+const MaxPageSize = 100
+
 type TodoHandler struct {
 	TodoService service.Service
 	jsonResponder response.JSONResponder
@@ -26,14 +27,14 @@ func NewTodoHandler(r *mux.Router, todoService service.Service) {
 	}
 
 	v1 := r.PathPrefix("/todo").Subrouter()
-	v1.Handle("", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.Create))).Methods(http.MethodPost)
-	v1.Handle("", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.GetAll))).Methods(http.MethodGet)
-	v1.Handle("/search", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.GetByTitle))).Methods(http.MethodGet)
-	v1.Handle("/{id}", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.GetByID))).Methods(http.MethodGet)
-	v1.Handle("/done/{id}", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.MarkAsDone))).Methods(http.MethodPut)
-	v1.Handle("/favorite/{id}", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.MarkAsFavorite))).Methods(http.MethodPut)
-	v1.Handle("/{id}",handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.UpdateData))).Methods(http.MethodPut)
-	v1.Handle("/{id}", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(handler.DeleteByID))).Methods(http.MethodDelete)
+	v1.Handle("", http.HandlerFunc(handler.Create)).Methods(http.MethodPost)
+	v1.Handle("", http.HandlerFunc(handler.GetAll)).Methods(http.MethodGet)
+	v1.Handle("/search", http.HandlerFunc(handler.GetByTitle)).Methods(http.MethodGet)
+	v1.Handle("/{id}", http.HandlerFunc(handler.GetByID)).Methods(http.MethodGet)
+	v1.Handle("/done/{id}", http.HandlerFunc(handler.MarkAsDone)).Methods(http.MethodPut)
+	v1.Handle("/favorite/{id}", http.HandlerFunc(handler.MarkAsFavorite)).Methods(http.MethodPut)
+	v1.Handle("/{id}",http.HandlerFunc(handler.UpdateData)).Methods(http.MethodPut)
+	v1.Handle("/{id}", http.HandlerFunc(handler.DeleteByID)).Methods(http.MethodDelete)
 }
 
 func (c *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -46,7 +47,7 @@ func (c *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := c.TodoService.Create(r.Context(), formData)
 	if err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
@@ -73,9 +74,29 @@ func (c *TodoHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		"is_favorite": isFavorite,
 	}
 
+	// limit pages through the todos in id order; after_id is the last id
+	// of the previous page.
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > MaxPageSize {
+			c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "limit should be a number from 1 to "+strconv.Itoa(MaxPageSize)))
+			return
+		}
+		params["limit"] = limit
+	}
+
+	if afterIDStr := r.URL.Query().Get("after_id"); afterIDStr != "" {
+		afterID, err := strconv.Atoi(afterIDStr)
+		if err != nil || afterID < 0 {
+			c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "after_id should be a positive number"))
+			return
+		}
+		params["after_id"] = afterID
+	}
+
 	resp, err := c.TodoService.GetAll(r.Context(), params)
 	if err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
@@ -97,7 +118,7 @@ func (c *TodoHandler) GetByTitle(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := c.TodoService.GetByTitle(r.Context(), params)
 	if err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
@@ -119,7 +140,7 @@ func (c *TodoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := c.TodoService.GetByID(r.Context(), id)
 	if err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
@@ -147,7 +168,7 @@ func (c *TodoHandler) UpdateData(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := c.TodoService.UpdateData(r.Context(), id, formData)
 	if err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
@@ -174,7 +195,7 @@ func (c *TodoHandler) MarkAsDone(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := c.TodoService.MarkAsDone(r.Context(), id, formData); err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
@@ -202,7 +223,7 @@ func (c *TodoHandler) MarkAsFavorite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := c.TodoService.MarkAsFavorite(r.Context(), id, formData); err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
@@ -224,7 +245,7 @@ func (c *TodoHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := c.TodoService.DeleteByID(r.Context(), id); err != nil {
-		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
 		return
 	}
 
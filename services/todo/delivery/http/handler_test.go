@@ -0,0 +1,149 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+)
+
+// slowRepository answers GetAll only once ctx is done, mapping the
+// context error the way TodoRepository does, and reports what it saw.
+type slowRepository struct {
+	repository.Repository
+	started chan struct{}
+	seen    chan error
+}
+
+func newSlowRepository() *slowRepository {
+	return &slowRepository{
+		started: make(chan struct{}, 1),
+		seen:    make(chan error, 1),
+	}
+}
+
+func (c *slowRepository) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	c.started <- struct{}{}
+	<-ctx.Done()
+	c.seen <- ctx.Err()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, repository.ErrTimeout
+	}
+	return nil, repository.ErrCanceled
+}
+
+// newTodoServer serves NewTodoHandler over repo and sends the status of
+// every response on the returned channel, which the client may not see.
+func newTodoServer(repo repository.Repository) (*httptest.Server, <-chan int) {
+	r := mux.NewRouter()
+	NewTodoHandler(r, service.NewTodoService(repo, event.NewBroker()))
+
+	statuses := make(chan int, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rw := response.NewStatusRecorder(w)
+		r.ServeHTTP(rw, req)
+		statuses <- rw.Code()
+	}))
+
+	return server, statuses
+}
+
+func TestClientCancelReachesRepository(t *testing.T) {
+	repo := newSlowRepository()
+	server, statuses := newTodoServer(repo)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/todo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err == nil {
+			resp.Body.Close()
+		}
+		errs <- err
+	}()
+
+	waitFor(t, repo.started, "the repository call")
+	cancel()
+
+	if err := receiveErr(t, repo.seen, "the repository to see the cancel"); err != context.Canceled {
+		t.Errorf("repository saw %v, want %v", err, context.Canceled)
+	}
+	if status := receiveStatus(t, statuses, "the response"); status != response.StatusClientClosedRequest {
+		t.Errorf("status = %d, want %d", status, response.StatusClientClosedRequest)
+	}
+	if err := <-errs; err == nil {
+		t.Error("client got a response after canceling")
+	}
+}
+
+func TestRepositoryTimeoutAnswers503(t *testing.T) {
+	repo := newSlowRepository()
+	server, statuses := newTodoServer(&repository.TimeoutRepository{
+		Next:    repo,
+		Default: 50 * time.Millisecond,
+	})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/todo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if status := receiveStatus(t, statuses, "the response"); status != http.StatusServiceUnavailable {
+		t.Errorf("recorded status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	if err := receiveErr(t, repo.seen, "the repository to time out"); err != context.DeadlineExceeded {
+		t.Errorf("repository saw %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func receiveErr(t *testing.T, ch <-chan error, what string) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+		return nil
+	}
+}
+
+func receiveStatus(t *testing.T, ch <-chan int, what string) int {
+	t.Helper()
+	select {
+	case status := <-ch:
+		return status
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+		return 0
+	}
+}
@@ -0,0 +1,15 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/health"
+	"github.com/gorilla/mux"
+)
+
+// NewHealthHandler serves the liveness probe at /healthz and the readiness
+// probe at /readyz.
+func NewHealthHandler(r *mux.Router, h *health.Health) {
+	r.Handle("/healthz", http.HandlerFunc(h.Live)).Methods(http.MethodGet)
+	r.Handle("/readyz", http.HandlerFunc(h.Ready)).Methods(http.MethodGet)
+}
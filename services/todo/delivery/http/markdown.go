@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/message"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+)
+
+type MarkdownHandler struct {
+	MarkdownService service.MarkdownService
+	jsonResponder   response.JSONResponder
+}
+
+func NewMarkdownHandler(r *mux.Router, markdownService service.MarkdownService) {
+	handler := &MarkdownHandler{
+		MarkdownService: markdownService,
+		jsonResponder:   response.NewDefaultJSONResponder(),
+	}
+
+	r.Handle("/todo/markdown/sync", http.HandlerFunc(handler.Sync)).Methods(http.MethodPost)
+}
+
+func (c *MarkdownHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	formData := new(todo.MarkdownSyncRequest)
+	if err := json.NewDecoder(r.Body).Decode(&formData); err != nil {
+		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "invalid json body"))
+		return
+	}
+
+	resp, err := c.MarkdownService.Sync(r.Context(), formData)
+	if err != nil {
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
+		return
+	}
+
+	c.jsonResponder.Data(w, http.StatusOK, resp)
+	return
+}
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/openapi"
+	"github.com/gorilla/mux"
+)
+
+// NewMetricsHandler serves Prometheus metrics at /metrics on the API
+// router. main only calls it when metrics have no port of their own.
+func NewMetricsHandler(r *mux.Router, handler http.Handler, doc *openapi.Document) {
+	r.Handle("/metrics", handler).Methods(http.MethodGet)
+
+	doc.Add("/metrics", http.MethodGet, &openapi.Operation{
+		OperationID: "metrics",
+		Summary:     "Prometheus metrics",
+		Responses: map[string]openapi.Response{
+			"200": {
+				Description: "metrics in the Prometheus text exposition format",
+				Content: map[string]openapi.MediaType{
+					"text/plain": {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,599 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/openapi"
+	"github.com/ardiantirta/todo-crud/services/todo/delivery/caldav"
+	"github.com/gorilla/mux"
+)
+
+// NewOpenAPIHandler serves the document at /openapi.json.
+func NewOpenAPIHandler(r *mux.Router, doc *openapi.Document) {
+	r.Handle("/openapi.json", doc).Methods(http.MethodGet)
+}
+
+// NewOpenAPIDocument describes every route the todo service registers.
+// TestRoutesAreDocumented in main_test.go fails when a route main registers
+// is missing from it.
+func NewOpenAPIDocument() *openapi.Document {
+	doc := openapi.New("todo-crud", "1.0.0")
+
+	boolString := []interface{}{"true", "false"}
+	optionalBoolString := []interface{}{"", "true", "false"}
+
+	doc.Components.Schemas["ErrorResponse"] = object(map[string]*openapi.Schema{
+		"code":    {Type: "integer"},
+		"message": {Type: "string"},
+	})
+	doc.Components.Schemas["Status"] = object(map[string]*openapi.Schema{
+		"status": {Type: "boolean"},
+	})
+	doc.Components.Schemas["Todo"] = object(map[string]*openapi.Schema{
+		"ID":          {Type: "integer"},
+		"CreatedAt":   {Type: "string", Format: "date-time"},
+		"UpdatedAt":   {Type: "string", Format: "date-time"},
+		"DeletedAt":   {Type: "string", Format: "date-time", Nullable: true},
+		"title":       {Type: "string"},
+		"description": {Type: "string"},
+		"is_done":     {Type: "boolean"},
+		"is_favorite": {Type: "boolean"},
+		"external_id": {Type: "string"},
+	})
+	doc.Components.Schemas["CreateRequest"] = object(map[string]*openapi.Schema{
+		"title":       {Type: "string", MinLength: openapi.Int(3), MaxLength: openapi.Int(100)},
+		"description": {Type: "string", MinLength: openapi.Int(10)},
+		"external_id": {Type: "string"},
+	}, "title", "description")
+	doc.Components.Schemas["UpdateRequest"] = object(map[string]*openapi.Schema{
+		"title":       {Type: "string", MinLength: openapi.Int(3), MaxLength: openapi.Int(100)},
+		"description": {Type: "string", MinLength: openapi.Int(10)},
+		"is_done":     {Type: "string", Enum: optionalBoolString},
+		"is_favorite": {Type: "string", Enum: optionalBoolString},
+	}, "title", "description")
+	doc.Components.Schemas["DoneRequest"] = object(map[string]*openapi.Schema{
+		"is_done": {Type: "string", Enum: boolString},
+	}, "is_done")
+	doc.Components.Schemas["FavoriteRequest"] = object(map[string]*openapi.Schema{
+		"is_favorite": {Type: "string", Enum: boolString},
+	}, "is_favorite")
+	doc.Components.Schemas["SyncFields"] = object(map[string]*openapi.Schema{
+		"title":       {Type: "string", Nullable: true},
+		"description": {Type: "string", Nullable: true},
+		"is_done":     {Type: "string", Enum: boolString, Nullable: true},
+		"is_favorite": {Type: "string", Enum: boolString, Nullable: true},
+	})
+	doc.Components.Schemas["SyncTimes"] = object(map[string]*openapi.Schema{
+		"title":       {Type: "string", Format: "date-time", Nullable: true},
+		"description": {Type: "string", Format: "date-time", Nullable: true},
+		"is_done":     {Type: "string", Format: "date-time", Nullable: true},
+		"is_favorite": {Type: "string", Format: "date-time", Nullable: true},
+	})
+	doc.Components.Schemas["SyncChange"] = object(map[string]*openapi.Schema{
+		"client_id":         {Type: "string"},
+		"id":                {Type: "integer"},
+		"deleted":           {Type: "boolean"},
+		"changed_at":        {Type: "string", Format: "date-time"},
+		"fields":            openapi.Ref("SyncFields"),
+		"base":              openapi.Ref("SyncFields"),
+		"fields_changed_at": openapi.Ref("SyncTimes"),
+	}, "client_id", "changed_at")
+	doc.Components.Schemas["SyncRequest"] = object(map[string]*openapi.Schema{
+		"token":   {Type: "string"},
+		"changes": {Type: "array", Items: openapi.Ref("SyncChange")},
+	})
+	doc.Components.Schemas["Tombstone"] = object(map[string]*openapi.Schema{
+		"id":         {Type: "integer"},
+		"deleted_at": {Type: "string", Format: "date-time"},
+	})
+	doc.Components.Schemas["ChangesResponse"] = object(map[string]*openapi.Schema{
+		"todos":      {Type: "array", Items: openapi.Ref("Todo")},
+		"tombstones": {Type: "array", Items: openapi.Ref("Tombstone")},
+		"token":      {Type: "string"},
+	})
+	doc.Components.Schemas["SyncResult"] = object(map[string]*openapi.Schema{
+		"client_id":     {Type: "string"},
+		"id":            {Type: "integer"},
+		"status":        {Type: "string", Enum: []interface{}{"applied", "merged", "rejected"}},
+		"server_fields": {Type: "array", Items: &openapi.Schema{Type: "string"}},
+		"error":         {Type: "string"},
+		"todo":          openapi.Ref("Todo"),
+	})
+	doc.Components.Schemas["SyncResponse"] = object(map[string]*openapi.Schema{
+		"results": {Type: "array", Items: openapi.Ref("SyncResult")},
+	})
+	doc.Components.Schemas["ImportRow"] = object(map[string]*openapi.Schema{
+		"external_id": {Type: "string"},
+		"title":       {Type: "string"},
+		"description": {Type: "string"},
+		"is_done":     {Type: "boolean"},
+		"is_favorite": {Type: "boolean"},
+	}, "title", "description")
+	doc.Components.Schemas["ImportError"] = object(map[string]*openapi.Schema{
+		"row":         {Type: "integer"},
+		"external_id": {Type: "string"},
+		"error":       {Type: "string"},
+	})
+	doc.Components.Schemas["ImportResponse"] = object(map[string]*openapi.Schema{
+		"dry_run": {Type: "boolean"},
+		"total":   {Type: "integer"},
+		"created": {Type: "integer"},
+		"updated": {Type: "integer"},
+		"failed":  {Type: "integer"},
+		"aborted": {Type: "boolean"},
+		"errors":  {Type: "array", Items: openapi.Ref("ImportError")},
+	})
+	doc.Components.Schemas["MarkdownSyncRequest"] = object(map[string]*openapi.Schema{
+		"document": {Type: "string"},
+		"dry_run":  {Type: "boolean"},
+	}, "document")
+	doc.Components.Schemas["MarkdownChange"] = object(map[string]*openapi.Schema{
+		"line":  {Type: "integer"},
+		"id":    {Type: "integer"},
+		"title": {Type: "string"},
+	})
+	doc.Components.Schemas["MarkdownSyncResponse"] = object(map[string]*openapi.Schema{
+		"dry_run":   {Type: "boolean"},
+		"created":   {Type: "array", Items: openapi.Ref("MarkdownChange")},
+		"updated":   {Type: "array", Items: openapi.Ref("MarkdownChange")},
+		"unchanged": {Type: "integer"},
+		"deleted":   {Type: "array", Items: openapi.Ref("MarkdownChange")},
+		"errors":    {Type: "array", Items: openapi.Ref("ImportError")},
+		"document":  {Type: "string"},
+	})
+	doc.Components.Schemas["HealthComponent"] = object(map[string]*openapi.Schema{
+		"status":     {Type: "string", Enum: []interface{}{"ok", "fail"}},
+		"detail":     {Type: "string"},
+		"error":      {Type: "string"},
+		"latency_ms": {Type: "number"},
+	}, "status", "latency_ms")
+	doc.Components.Schemas["HealthReport"] = object(map[string]*openapi.Schema{
+		"status":     {Type: "string", Enum: []interface{}{"ok", "fail", "draining"}},
+		"checked_at": {Type: "string", Format: "date-time"},
+		"components": {Type: "object", AdditionalProperties: openapi.Ref("HealthComponent")},
+	}, "status", "checked_at")
+	doc.Components.Schemas["GraphQLRequest"] = object(map[string]*openapi.Schema{
+		"query":         {Type: "string"},
+		"variables":     {Type: "object", Nullable: true},
+		"operationName": {Type: "string", Nullable: true},
+	}, "query")
+
+	id := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	status := jsonResponse("status", openapi.Ref("Status"))
+
+	doc.Add("/", http.MethodGet, &openapi.Operation{
+		OperationID: "index",
+		Summary:     "Service status",
+		Responses:   map[string]openapi.Response{"200": status},
+	})
+	health := jsonResponse("health report", openapi.Ref("HealthReport"))
+	doc.Add("/healthz", http.MethodGet, &openapi.Operation{
+		OperationID: "liveness",
+		Summary:     "Liveness probe: the process serves requests",
+		Responses:   map[string]openapi.Response{"200": health},
+	})
+	doc.Add("/readyz", http.MethodGet, &openapi.Operation{
+		OperationID: "readiness",
+		Summary:     "Readiness probe: every dependency works and the server is not draining",
+		Responses:   map[string]openapi.Response{"200": health, "503": health},
+	})
+	doc.Add("/openapi.json", http.MethodGet, &openapi.Operation{
+		OperationID: "openapi",
+		Summary:     "This document",
+		Responses: map[string]openapi.Response{
+			"200": jsonResponse("OpenAPI document", &openapi.Schema{Type: "object"}),
+		},
+	})
+
+	doc.Add("/todo", http.MethodPost, &openapi.Operation{
+		OperationID: "createTodo",
+		Tags:        []string{"todo"},
+		RequestBody: jsonBody("CreateRequest"),
+		Responses:   dataResponses(openapi.Ref("Todo")),
+	})
+	doc.Add("/todo", http.MethodGet, &openapi.Operation{
+		OperationID: "getAllTodo",
+		Tags:        []string{"todo"},
+		Parameters: []openapi.Parameter{
+			{Name: "is_done", In: "query", Schema: &openapi.Schema{Type: "string", Enum: optionalBoolString}},
+			{Name: "is_favorite", In: "query", Schema: &openapi.Schema{Type: "string", Enum: optionalBoolString}},
+			{Name: "limit", In: "query", Description: "page size; pages are in id order", Schema: &openapi.Schema{Type: "integer"}},
+			{Name: "after_id", In: "query", Description: "last id of the previous page", Schema: &openapi.Schema{Type: "integer"}},
+		},
+		Responses: dataResponses(&openapi.Schema{Type: "array", Items: openapi.Ref("Todo")}),
+	})
+	doc.Add("/todo/search", http.MethodGet, &openapi.Operation{
+		OperationID: "searchTodo",
+		Tags:        []string{"todo"},
+		Parameters: []openapi.Parameter{
+			{Name: "title", In: "query", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: dataResponses(&openapi.Schema{Type: "array", Items: openapi.Ref("Todo")}),
+	})
+	doc.Add("/todo/{id}", http.MethodGet, &openapi.Operation{
+		OperationID: "getTodo",
+		Tags:        []string{"todo"},
+		Parameters:  []openapi.Parameter{id},
+		Responses:   notFound(dataResponses(openapi.Ref("Todo"))),
+	})
+	doc.Add("/todo/{id}", http.MethodPut, &openapi.Operation{
+		OperationID: "updateTodo",
+		Tags:        []string{"todo"},
+		Parameters:  []openapi.Parameter{id},
+		RequestBody: jsonBody("UpdateRequest"),
+		Responses:   notFound(dataResponses(openapi.Ref("Todo"))),
+	})
+	doc.Add("/todo/{id}", http.MethodDelete, &openapi.Operation{
+		OperationID: "deleteTodo",
+		Tags:        []string{"todo"},
+		Parameters:  []openapi.Parameter{id},
+		Responses:   notFound(statusResponses(status)),
+	})
+	doc.Add("/todo/done/{id}", http.MethodPut, &openapi.Operation{
+		OperationID: "markTodoAsDone",
+		Tags:        []string{"todo"},
+		Parameters:  []openapi.Parameter{id},
+		RequestBody: jsonBody("DoneRequest"),
+		Responses:   notFound(statusResponses(status)),
+	})
+	doc.Add("/todo/favorite/{id}", http.MethodPut, &openapi.Operation{
+		OperationID: "markTodoAsFavorite",
+		Tags:        []string{"todo"},
+		Parameters:  []openapi.Parameter{id},
+		RequestBody: jsonBody("FavoriteRequest"),
+		Responses:   notFound(statusResponses(status)),
+	})
+
+	formats := []interface{}{"csv", "json", "ndjson", "todotxt", "ics", "markdown"}
+	doc.Add("/todo/export", http.MethodGet, &openapi.Operation{
+		OperationID: "exportTodo",
+		Summary:     "Stream every todo matching the filters as a file",
+		Tags:        []string{"todo"},
+		Parameters: []openapi.Parameter{
+			{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string", Enum: formats}},
+			{Name: "is_done", In: "query", Schema: &openapi.Schema{Type: "string", Enum: optionalBoolString}},
+			{Name: "is_favorite", In: "query", Schema: &openapi.Schema{Type: "string", Enum: optionalBoolString}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": {
+				Description: "exported todos",
+				Content: map[string]openapi.MediaType{
+					"text/csv":             {Schema: &openapi.Schema{Type: "string"}},
+					"application/json":     {Schema: &openapi.Schema{Type: "array", Items: openapi.Ref("Todo")}},
+					"application/x-ndjson": {Schema: &openapi.Schema{Type: "string"}},
+					"text/plain":           {Schema: &openapi.Schema{Type: "string"}},
+					"text/calendar":        {Schema: &openapi.Schema{Type: "string"}},
+					"text/markdown":        {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+			"400": jsonResponse("error", openapi.Ref("ErrorResponse")),
+			"500": jsonResponse("error before any todo was written", openapi.Ref("ErrorResponse")),
+		},
+	})
+	doc.Add("/todo/import", http.MethodPost, &openapi.Operation{
+		OperationID: "importTodo",
+		Summary:     "Create or update todos from a file, upserting by external_id",
+		Tags:        []string{"todo"},
+		Parameters: []openapi.Parameter{
+			{Name: "format", In: "query", Description: "defaults to the Content-Type", Schema: &openapi.Schema{Type: "string", Enum: formats}},
+			{Name: "dry_run", In: "query", Schema: &openapi.Schema{Type: "string", Enum: boolString}},
+			{Name: "map", In: "query", Description: "csv column mapping, e.g. title:Task,description:Notes", Schema: &openapi.Schema{Type: "string"}},
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Streamed: true,
+			Content: map[string]openapi.MediaType{
+				"text/csv":             {Schema: &openapi.Schema{Type: "string"}},
+				"application/json":     {Schema: &openapi.Schema{Type: "array", Items: openapi.Ref("ImportRow")}},
+				"application/x-ndjson": {Schema: &openapi.Schema{Type: "string"}},
+				"text/plain":           {Schema: &openapi.Schema{Type: "string"}},
+				"text/calendar":        {Schema: &openapi.Schema{Type: "string"}},
+				"text/markdown":        {Schema: &openapi.Schema{Type: "string"}},
+			},
+		},
+		Responses: dataResponses(openapi.Ref("ImportResponse")),
+	})
+
+	doc.Add("/todo/markdown/sync", http.MethodPost, &openapi.Operation{
+		OperationID: "syncMarkdown",
+		Summary:     "Sync a Markdown checklist, matching items by their todo id comment",
+		Tags:        []string{"todo"},
+		RequestBody: jsonBody("MarkdownSyncRequest"),
+		Responses:   dataResponses(openapi.Ref("MarkdownSyncResponse")),
+	})
+
+	doc.Add("/calendar/{token}.ics", http.MethodGet, &openapi.Operation{
+		OperationID: "calendarFeed",
+		Summary:     "iCalendar feed of every todo for calendar subscriptions",
+		Tags:        []string{"todo"},
+		Parameters: []openapi.Parameter{
+			{Name: "token", In: "path", Required: true, Description: "calendar.token from the config", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": {
+				Description: "VTODO components",
+				Content: map[string]openapi.MediaType{
+					"text/calendar": {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+			"404": {Description: "unknown token or the feed is disabled"},
+		},
+	})
+
+	doc.Add("/sync", http.MethodGet, &openapi.Operation{
+		OperationID: "getChanges",
+		Tags:        []string{"sync"},
+		Parameters: []openapi.Parameter{
+			{Name: "since", In: "query", Description: "token from the previous sync", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: dataResponses(openapi.Ref("ChangesResponse")),
+	})
+	doc.Add("/sync", http.MethodPost, &openapi.Operation{
+		OperationID: "sync",
+		Tags:        []string{"sync"},
+		RequestBody: jsonBody("SyncRequest"),
+		Responses:   dataResponses(openapi.Ref("SyncResponse")),
+	})
+
+	doc.Add("/ws", http.MethodGet, &openapi.Operation{
+		OperationID: "websocket",
+		Summary:     "Websocket channel for todo events and mutations",
+		Tags:        []string{"realtime"},
+		Parameters: []openapi.Parameter{
+			{Name: "name", In: "query", Description: "name shown to other subscribers, not verified", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]openapi.Response{
+			"101": {Description: "switching protocols"},
+			"403": {Description: "origin not allowed"},
+		},
+	})
+
+	graphqlResponses := map[string]openapi.Response{
+		"200": jsonResponse("GraphQL result", &openapi.Schema{Type: "object"}),
+		"400": jsonResponse("error", openapi.Ref("ErrorResponse")),
+	}
+	doc.Add("/graphql", http.MethodGet, &openapi.Operation{
+		OperationID: "graphqlQuery",
+		Tags:        []string{"graphql"},
+		Parameters: []openapi.Parameter{
+			{Name: "query", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			{Name: "variables", In: "query", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "operationName", In: "query", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": graphqlResponses["200"],
+			"400": graphqlResponses["400"],
+			"405": jsonResponse("mutations and subscriptions need POST", openapi.Ref("ErrorResponse")),
+		},
+	})
+	doc.Add("/graphql", http.MethodPost, &openapi.Operation{
+		OperationID: "graphql",
+		Tags:        []string{"graphql"},
+		RequestBody: jsonBody("GraphQLRequest"),
+		Responses:   graphqlResponses,
+	})
+
+	// CalDAV is served under the /dav/ prefix: the operations below apply
+	// to /dav/ and every resource under it.
+	multistatus := openapi.Response{
+		Description: "WebDAV multistatus",
+		Content: map[string]openapi.MediaType{
+			"application/xml": {Schema: &openapi.Schema{Type: "string"}},
+		},
+	}
+	calendarObject := openapi.Response{
+		Description: "VTODO calendar object, with its ETag",
+		Content: map[string]openapi.MediaType{
+			"text/calendar": {Schema: &openapi.Schema{Type: "string"}},
+		},
+	}
+	for _, method := range caldav.DiscoveryMethods {
+		doc.Add("/.well-known/caldav", method, &openapi.Operation{
+			OperationID: "caldavDiscovery" + strings.Title(strings.ToLower(method)),
+			Summary:     "Redirect to the CalDAV root",
+			Tags:        []string{"caldav"},
+			Responses: map[string]openapi.Response{
+				"301": {Description: "the CalDAV root, /dav/"},
+			},
+		})
+	}
+	doc.Add("/dav/", http.MethodOptions, &openapi.Operation{
+		OperationID: "caldavOptions",
+		Summary:     "DAV capabilities",
+		Tags:        []string{"caldav"},
+		Responses: map[string]openapi.Response{
+			"200": {Description: "DAV and Allow headers"},
+		},
+	})
+	doc.Add("/dav/", "PROPFIND", &openapi.Operation{
+		OperationID: "caldavPropfind",
+		Summary:     "Properties of the root, the calendar home, the calendar or a todo",
+		Tags:        []string{"caldav"},
+		Responses: map[string]openapi.Response{
+			"207": multistatus,
+			"404": {Description: "unknown resource"},
+		},
+	})
+	doc.Add("/dav/", "REPORT", &openapi.Operation{
+		OperationID: "caldavReport",
+		Summary:     "calendar-query or calendar-multiget report on the calendar",
+		Tags:        []string{"caldav"},
+		Responses: map[string]openapi.Response{
+			"207": multistatus,
+			"400": {Description: "unsupported report"},
+			"405": {Description: "not the calendar"},
+		},
+	})
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		doc.Add("/dav/", method, &openapi.Operation{
+			OperationID: "caldav" + strings.Title(strings.ToLower(method)),
+			Summary:     "A todo as a calendar object",
+			Tags:        []string{"caldav"},
+			Responses: map[string]openapi.Response{
+				"200": calendarObject,
+				"404": {Description: "unknown todo"},
+				"405": {Description: "a collection"},
+			},
+		})
+	}
+	doc.Add("/dav/", http.MethodPut, &openapi.Operation{
+		OperationID: "caldavPut",
+		Summary:     "Create or update a todo from a calendar object, guarded by If-Match or If-None-Match",
+		Tags:        []string{"caldav"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"text/calendar": {Schema: &openapi.Schema{Type: "string"}},
+			},
+		},
+		Responses: map[string]openapi.Response{
+			"201": {Description: "created, with the new ETag"},
+			"204": {Description: "updated, with the new ETag"},
+			"400": {Description: "invalid calendar object"},
+			"412": {Description: "the ETag does not match"},
+		},
+	})
+	doc.Add("/dav/", http.MethodDelete, &openapi.Operation{
+		OperationID: "caldavDelete",
+		Summary:     "Delete a todo, guarded by If-Match",
+		Tags:        []string{"caldav"},
+		Responses: map[string]openapi.Response{
+			"204": {Description: "deleted"},
+			"404": {Description: "unknown todo"},
+			"412": {Description: "the ETag does not match"},
+		},
+	})
+
+	htmlPage := map[string]openapi.Response{
+		"200": htmlResponse("HTML page"),
+		"400": htmlResponse("HTML page with the error"),
+	}
+	formRedirect := map[string]openapi.Response{
+		"303": {Description: "back to the list"},
+		"400": htmlResponse("HTML page with the error"),
+		"403": {Description: "invalid csrf token"},
+	}
+	doc.Components.Schemas["TodoForm"] = object(map[string]*openapi.Schema{
+		"csrf_token":  {Type: "string"},
+		"back":        {Type: "string"},
+		"title":       {Type: "string"},
+		"description": {Type: "string"},
+		"is_done":     {Type: "string"},
+		"is_favorite": {Type: "string"},
+	}, "csrf_token")
+
+	doc.Add("/ui", http.MethodGet, &openapi.Operation{
+		OperationID: "uiIndex",
+		Tags:        []string{"ui"},
+		Parameters: []openapi.Parameter{
+			{Name: "q", In: "query", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "is_done", In: "query", Schema: &openapi.Schema{Type: "string", Enum: optionalBoolString}},
+			{Name: "is_favorite", In: "query", Schema: &openapi.Schema{Type: "string", Enum: optionalBoolString}},
+		},
+		Responses: htmlPage,
+	})
+	doc.Add("/ui/static/{file}", http.MethodGet, &openapi.Operation{
+		OperationID: "uiStatic",
+		Tags:        []string{"ui"},
+		Parameters: []openapi.Parameter{
+			{Name: "file", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]openapi.Response{
+			"200": {Description: "stylesheet or script"},
+			"404": {Description: "unknown asset"},
+		},
+	})
+	doc.Add("/ui/todos/{id}/edit", http.MethodGet, &openapi.Operation{
+		OperationID: "uiEdit",
+		Tags:        []string{"ui"},
+		Parameters:  []openapi.Parameter{id},
+		Responses:   htmlPage,
+	})
+	doc.Add("/ui/todos", http.MethodPost, &openapi.Operation{
+		OperationID: "uiCreate",
+		Tags:        []string{"ui"},
+		RequestBody: formBody(),
+		Responses:   formRedirect,
+	})
+	for path, operationID := range map[string]string{
+		"/ui/todos/{id}":          "uiUpdate",
+		"/ui/todos/{id}/done":     "uiMarkAsDone",
+		"/ui/todos/{id}/favorite": "uiMarkAsFavorite",
+		"/ui/todos/{id}/delete":   "uiDelete",
+	} {
+		doc.Add(path, http.MethodPost, &openapi.Operation{
+			OperationID: operationID,
+			Tags:        []string{"ui"},
+			Parameters:  []openapi.Parameter{id},
+			RequestBody: formBody(),
+			Responses:   formRedirect,
+		})
+	}
+
+	return doc
+}
+
+func htmlResponse(description string) openapi.Response {
+	return openapi.Response{
+		Description: description,
+		Content: map[string]openapi.MediaType{
+			"text/html": {Schema: &openapi.Schema{Type: "string"}},
+		},
+	}
+}
+
+func formBody() *openapi.RequestBody {
+	return &openapi.RequestBody{
+		Required: true,
+		Content: map[string]openapi.MediaType{
+			"application/x-www-form-urlencoded": {Schema: openapi.Ref("TodoForm")},
+		},
+	}
+}
+
+func object(properties map[string]*openapi.Schema, required ...string) *openapi.Schema {
+	return &openapi.Schema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+}
+
+func jsonBody(name string) *openapi.RequestBody {
+	return &openapi.RequestBody{
+		Required: true,
+		Content: map[string]openapi.MediaType{
+			response.JSONContentType: {Schema: openapi.Ref(name)},
+		},
+	}
+}
+
+func jsonResponse(description string, schema *openapi.Schema) openapi.Response {
+	return openapi.Response{
+		Description: description,
+		Content: map[string]openapi.MediaType{
+			response.JSONContentType: {Schema: schema},
+		},
+	}
+}
+
+func dataResponses(schema *openapi.Schema) map[string]openapi.Response {
+	return statusResponses(jsonResponse("success", object(map[string]*openapi.Schema{
+		"data": schema,
+	})))
+}
+
+// notFound adds the 404 of operations on a single todo.
+func notFound(responses map[string]openapi.Response) map[string]openapi.Response {
+	responses["404"] = jsonResponse("todo not found", openapi.Ref("ErrorResponse"))
+	return responses
+}
+
+func statusResponses(ok openapi.Response) map[string]openapi.Response {
+	return map[string]openapi.Response{
+		"200": ok,
+		"400": jsonResponse("error", openapi.Ref("ErrorResponse")),
+	}
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/message"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+)
+
+type SyncHandler struct {
+	SyncService   service.SyncService
+	jsonResponder response.JSONResponder
+}
+
+func NewSyncHandler(r *mux.Router, syncService service.SyncService) {
+	handler := &SyncHandler{
+		SyncService:   syncService,
+		jsonResponder: response.NewDefaultJSONResponder(),
+	}
+
+	r.Handle("/sync", http.HandlerFunc(handler.Changes)).Methods(http.MethodGet)
+	r.Handle("/sync", http.HandlerFunc(handler.Sync)).Methods(http.MethodPost)
+}
+
+func (c *SyncHandler) Changes(w http.ResponseWriter, r *http.Request) {
+	resp, err := c.SyncService.Changes(r.Context(), r.URL.Query().Get("since"))
+	if err != nil {
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
+		return
+	}
+
+	c.jsonResponder.Data(w, http.StatusOK, resp)
+	return
+}
+
+func (c *SyncHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	formData := new(todo.SyncRequest)
+	if err := json.NewDecoder(r.Body).Decode(&formData); err != nil {
+		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, "invalid json body"))
+		return
+	}
+
+	resp, err := c.SyncService.Sync(r.Context(), formData)
+	if err != nil {
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
+		return
+	}
+
+	c.jsonResponder.Data(w, http.StatusOK, resp)
+	return
+}
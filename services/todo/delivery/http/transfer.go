@@ -0,0 +1,149 @@
+package http
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/logging"
+	"github.com/ardiantirta/todo-crud/common/message"
+	"github.com/ardiantirta/todo-crud/services/todo/codec"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/gorilla/mux"
+)
+
+type TransferHandler struct {
+	TransferService service.TransferService
+	jsonResponder   response.JSONResponder
+}
+
+// NewTransferHandler registers /todo/export and /todo/import. It has to be
+// called before NewTodoHandler, whose /todo/{id} would match them first.
+func NewTransferHandler(r *mux.Router, transferService service.TransferService) {
+	handler := &TransferHandler{
+		TransferService: transferService,
+		jsonResponder:   response.NewDefaultJSONResponder(),
+	}
+
+	r.Handle("/todo/export", http.HandlerFunc(handler.Export)).Methods(http.MethodGet)
+	r.Handle("/todo/import", http.HandlerFunc(handler.Import)).Methods(http.MethodPost)
+}
+
+func (c *TransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = codec.FormatJSON
+	}
+
+	out := &trackingWriter{w: w}
+	enc, err := codec.NewEncoder(format, out)
+	if err != nil {
+		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", codec.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+codec.FileName(format)+`"`)
+
+	params := map[string]interface{}{
+		"is_done":     r.URL.Query().Get("is_done"),
+		"is_favorite": r.URL.Query().Get("is_favorite"),
+	}
+
+	if err := c.TransferService.Export(r.Context(), params, enc); err != nil {
+		// Once the body has started the status cannot change; the client
+		// sees a truncated file.
+		if out.written {
+			logging.FromContext(r.Context()).WithError(err).Error("export failed")
+			return
+		}
+
+		w.Header().Del("Content-Disposition")
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusInternalServerError), message.NewErrorMessage(0, err.Error()))
+		return
+	}
+}
+
+func (c *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatOf(r.Header.Get("Content-Type"))
+	}
+
+	mapping, err := parseMapping(r.URL.Query().Get("map"))
+	if err != nil {
+		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	dec, err := codec.NewDecoder(format, r.Body, mapping)
+	if err != nil {
+		c.jsonResponder.Error(w, http.StatusBadRequest, message.NewErrorMessage(0, err.Error()))
+		return
+	}
+
+	resp, err := c.TransferService.Import(r.Context(), dec, dryRun)
+	if err != nil {
+		c.jsonResponder.Error(w, errorStatus(err, http.StatusBadRequest), message.NewErrorMessage(0, err.Error()))
+		return
+	}
+
+	c.jsonResponder.Data(w, http.StatusOK, resp)
+	return
+}
+
+// formatOf picks the import format from a Content-Type header.
+func formatOf(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return codec.FormatCSV
+	case "application/x-ndjson", "application/ndjson":
+		return codec.FormatNDJSON
+	case "text/plain":
+		return codec.FormatTodoTxt
+	case "text/calendar":
+		return codec.FormatICS
+	case "text/markdown":
+		return codec.FormatMarkdown
+	}
+
+	return codec.FormatJSON
+}
+
+// parseMapping reads a CSV column mapping such as
+// "title:Task,description:Notes".
+func parseMapping(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if s == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || !codec.IsField(strings.TrimSpace(parts[0])) || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected field:column with field one of %s",
+				pair, strings.Join(codec.Fields, ", "))
+		}
+
+		mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return mapping, nil
+}
+
+// trackingWriter records whether anything reached the client yet.
+type trackingWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (c *trackingWriter) Write(p []byte) (int, error) {
+	c.written = true
+	return c.w.Write(p)
+}
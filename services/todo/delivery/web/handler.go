@@ -0,0 +1,328 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+)
+
+const csrfCookie = "csrf_token"
+
+type page struct {
+	Title      string
+	Error      string
+	CSRFToken  string
+	Back       string
+	Query      string
+	IsDone     string
+	IsFavorite string
+	Todos      []todo.ViewResponse
+	Todo       *todo.ViewResponse
+	Form       todo.CreateRequest
+}
+
+type asset struct {
+	contentType string
+	content     string
+}
+
+// TodoHandler serves the server-rendered UI under /ui. Every form works
+// without JavaScript; app.js only submits some of them in the background.
+type TodoHandler struct {
+	TodoService service.Service
+	list        *template.Template
+	edit        *template.Template
+	assets      map[string]asset
+}
+
+func NewTodoHandler(r *mux.Router, todoService service.Service) {
+	layout := template.Must(template.New("layout").Parse(layoutTemplate))
+
+	handler := &TodoHandler{
+		TodoService: todoService,
+		list:        template.Must(template.Must(layout.Clone()).Parse(listTemplate)),
+		edit:        template.Must(template.Must(layout.Clone()).Parse(editTemplate)),
+		assets: map[string]asset{
+			"app.css": {contentType: "text/css; charset=utf-8", content: appCSS},
+			"app.js":  {contentType: "application/javascript; charset=utf-8", content: appJS},
+		},
+	}
+
+	ui := r.PathPrefix("/ui").Subrouter()
+	ui.HandleFunc("", handler.Index).Methods(http.MethodGet)
+	ui.HandleFunc("/static/{file}", handler.Static).Methods(http.MethodGet)
+	ui.HandleFunc("/todos", handler.csrf(handler.Create)).Methods(http.MethodPost)
+	ui.HandleFunc("/todos/{id}/edit", handler.Edit).Methods(http.MethodGet)
+	ui.HandleFunc("/todos/{id}", handler.csrf(handler.Update)).Methods(http.MethodPost)
+	ui.HandleFunc("/todos/{id}/done", handler.csrf(handler.MarkAsDone)).Methods(http.MethodPost)
+	ui.HandleFunc("/todos/{id}/favorite", handler.csrf(handler.MarkAsFavorite)).Methods(http.MethodPost)
+	ui.HandleFunc("/todos/{id}/delete", handler.csrf(handler.Delete)).Methods(http.MethodPost)
+}
+
+func (c *TodoHandler) Index(w http.ResponseWriter, r *http.Request) {
+	c.renderList(w, r, http.StatusOK, "", todo.CreateRequest{})
+}
+
+func (c *TodoHandler) Static(w http.ResponseWriter, r *http.Request) {
+	a, ok := c.assets[mux.Vars(r)["file"]]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", a.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	_, _ = w.Write([]byte(a.content))
+}
+
+func (c *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
+	form := todo.CreateRequest{
+		Title:       r.PostFormValue("title"),
+		Description: r.PostFormValue("description"),
+	}
+
+	if _, err := c.TodoService.Create(r.Context(), &form); err != nil {
+		c.renderList(w, r, http.StatusBadRequest, err.Error(), form)
+		return
+	}
+
+	c.redirect(w, r)
+}
+
+func (c *TodoHandler) Edit(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.id(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := c.TodoService.GetByID(r.Context(), id)
+	if err != nil {
+		c.renderList(w, r, http.StatusNotFound, err.Error(), todo.CreateRequest{})
+		return
+	}
+
+	c.render(w, r, c.edit, http.StatusOK, &page{Title: "Edit", Back: backURL(r.URL.Query().Get("back")), Todo: resp})
+}
+
+func (c *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.id(w, r)
+	if !ok {
+		return
+	}
+
+	form := &todo.UpdateRequest{
+		Title:       r.PostFormValue("title"),
+		Description: r.PostFormValue("description"),
+		IsDone:      strconv.FormatBool(r.PostFormValue("is_done") == "true"),
+		IsFavorite:  strconv.FormatBool(r.PostFormValue("is_favorite") == "true"),
+	}
+
+	if _, err := c.TodoService.UpdateData(r.Context(), id, form); err != nil {
+		data := &todo.ViewResponse{
+			Title:       form.Title,
+			Description: form.Description,
+			IsDone:      form.IsDone == "true",
+			IsFavorite:  form.IsFavorite == "true",
+		}
+		data.ID = uint(id)
+
+		c.render(w, r, c.edit, http.StatusBadRequest, &page{Title: "Edit", Error: err.Error(), Back: backURL(r.PostFormValue("back")), Todo: data})
+		return
+	}
+
+	c.redirect(w, r)
+}
+
+func (c *TodoHandler) MarkAsDone(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.id(w, r)
+	if !ok {
+		return
+	}
+
+	form := &todo.DoneRequest{IsDone: r.PostFormValue("is_done")}
+	if err := c.TodoService.MarkAsDone(r.Context(), id, form); err != nil {
+		c.renderList(w, r, http.StatusBadRequest, err.Error(), todo.CreateRequest{})
+		return
+	}
+
+	c.redirect(w, r)
+}
+
+func (c *TodoHandler) MarkAsFavorite(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.id(w, r)
+	if !ok {
+		return
+	}
+
+	form := &todo.FavoriteRequest{IsFavorite: r.PostFormValue("is_favorite")}
+	if err := c.TodoService.MarkAsFavorite(r.Context(), id, form); err != nil {
+		c.renderList(w, r, http.StatusBadRequest, err.Error(), todo.CreateRequest{})
+		return
+	}
+
+	c.redirect(w, r)
+}
+
+func (c *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.id(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.TodoService.DeleteByID(r.Context(), id); err != nil {
+		c.renderList(w, r, http.StatusBadRequest, err.Error(), todo.CreateRequest{})
+		return
+	}
+
+	c.redirect(w, r)
+}
+
+// renderList renders the list page. The filters come from the query string
+// on GET, from its back parameter on the edit page, and from the hidden
+// back field after a form post.
+func (c *TodoHandler) renderList(w http.ResponseWriter, r *http.Request, status int, errMessage string, form todo.CreateRequest) {
+	query := r.URL.Query()
+	if r.Method == http.MethodPost {
+		query = backQuery(r.PostFormValue("back"))
+	} else if back := query.Get("back"); back != "" {
+		query = backQuery(back)
+	}
+
+	p := &page{
+		Title:      "Todos",
+		Error:      errMessage,
+		Query:      query.Get("q"),
+		IsDone:     query.Get("is_done"),
+		IsFavorite: query.Get("is_favorite"),
+		Form:       form,
+	}
+	p.Back = "/ui"
+	if encoded := query.Encode(); encoded != "" {
+		p.Back += "?" + encoded
+	}
+
+	var err error
+	if p.Query != "" {
+		p.Todos, err = c.TodoService.GetByTitle(r.Context(), map[string]interface{}{"title": p.Query})
+		p.Todos = filter(p.Todos, p.IsDone, p.IsFavorite)
+	} else {
+		p.Todos, err = c.TodoService.GetAll(r.Context(), map[string]interface{}{
+			"is_done":     p.IsDone,
+			"is_favorite": p.IsFavorite,
+		})
+	}
+	if err != nil && p.Error == "" {
+		p.Error = err.Error()
+		status = http.StatusInternalServerError
+	}
+
+	c.render(w, r, c.list, status, p)
+}
+
+func (c *TodoHandler) render(w http.ResponseWriter, r *http.Request, t *template.Template, status int, p *page) {
+	p.CSRFToken = c.token(w, r)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = t.ExecuteTemplate(w, "layout", p)
+}
+
+// redirect sends the browser back to the list it came from.
+func (c *TodoHandler) redirect(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, backURL(r.PostFormValue("back")), http.StatusSeeOther)
+}
+
+func (c *TodoHandler) id(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		c.renderList(w, r, http.StatusBadRequest, "id should be a positive number", todo.CreateRequest{})
+		return 0, false
+	}
+
+	return id, true
+}
+
+// token returns the CSRF token of the browser, issuing one when the
+// cookie is missing.
+func (c *TodoHandler) token(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(csrfCookie); err == nil && len(cookie.Value) == 64 {
+		return cookie.Value
+	}
+
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     "/ui",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	r.AddCookie(&http.Cookie{Name: csrfCookie, Value: token})
+
+	return token
+}
+
+// csrf rejects form posts whose token does not match the cookie.
+func (c *TodoHandler) csrf(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(csrfCookie)
+		if err != nil || cookie.Value == "" ||
+			subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.PostFormValue(csrfCookie))) != 1 {
+			http.Error(w, "invalid csrf token", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// backQuery returns the list filters carried in the back field. Anything
+// that is not a /ui URL is ignored so the field cannot redirect elsewhere.
+func backQuery(back string) url.Values {
+	if !strings.HasPrefix(back, "/ui?") {
+		return url.Values{}
+	}
+
+	query, err := url.ParseQuery(strings.TrimPrefix(back, "/ui?"))
+	if err != nil {
+		return url.Values{}
+	}
+
+	return query
+}
+
+// backURL is the list URL with the filters carried in the back field.
+func backURL(back string) string {
+	if query := backQuery(back).Encode(); query != "" {
+		return "/ui?" + query
+	}
+
+	return "/ui"
+}
+
+func filter(todos []todo.ViewResponse, isDone, isFavorite string) []todo.ViewResponse {
+	result := make([]todo.ViewResponse, 0, len(todos))
+	for _, t := range todos {
+		if isDone != "" && strconv.FormatBool(t.IsDone) != isDone {
+			continue
+		}
+		if isFavorite != "" && strconv.FormatBool(t.IsFavorite) != isFavorite {
+			continue
+		}
+		result = append(result, t)
+	}
+
+	return result
+}
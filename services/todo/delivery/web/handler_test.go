@@ -0,0 +1,152 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// fakeService serves a single todo and accepts every change to it.
+type fakeService struct {
+	service.Service
+}
+
+func (c *fakeService) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	return []todo.ViewResponse{{Model: gorm.Model{ID: 1}, Title: "Buy milk", Description: "Two liters, semi-skimmed"}}, nil
+}
+
+func (c *fakeService) GetByID(ctx context.Context, id int) (*todo.ViewResponse, error) {
+	return &todo.ViewResponse{Model: gorm.Model{ID: uint(id)}, Title: "Buy milk", Description: "Two liters, semi-skimmed"}, nil
+}
+
+func (c *fakeService) UpdateData(ctx context.Context, id int, form *todo.UpdateRequest) (*todo.ViewResponse, error) {
+	return c.GetByID(ctx, id)
+}
+
+func (c *fakeService) MarkAsDone(ctx context.Context, id int, form *todo.DoneRequest) error {
+	return nil
+}
+
+func newRouter() *mux.Router {
+	r := mux.NewRouter()
+	NewTodoHandler(r, &fakeService{})
+	return r
+}
+
+// csrfCookieFrom loads the list page and returns the CSRF cookie it sets.
+func csrfCookieFrom(t *testing.T, r http.Handler) *http.Cookie {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui", nil))
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == csrfCookie {
+			if !strings.Contains(w.Body.String(), `name="csrf_token" value="`+cookie.Value+`"`) {
+				t.Fatal("the page does not embed the token of its cookie")
+			}
+			return cookie
+		}
+	}
+
+	t.Fatal("GET /ui set no csrf cookie")
+	return nil
+}
+
+func post(r http.Handler, target string, cookie *http.Cookie, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestFormsNeedTheCSRFToken(t *testing.T) {
+	r := newRouter()
+	cookie := csrfCookieFrom(t, r)
+	other := &http.Cookie{Name: csrfCookie, Value: strings.Repeat("0", 64)}
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		token  string
+		status int
+	}{
+		{"no cookie", nil, cookie.Value, http.StatusForbidden},
+		{"no token", cookie, "", http.StatusForbidden},
+		{"another browser's token", other, cookie.Value, http.StatusForbidden},
+		{"matching token", cookie, cookie.Value, http.StatusSeeOther},
+	}
+
+	for _, tt := range tests {
+		form := url.Values{"is_done": {"true"}, "back": {"/ui?is_done=false"}}
+		if tt.token != "" {
+			form.Set(csrfCookie, tt.token)
+		}
+
+		w := post(r, "/ui/todos/1/done", tt.cookie, form)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if tt.status == http.StatusSeeOther && w.Header().Get("Location") != "/ui?is_done=false" {
+			t.Errorf("%s: redirect to %q, want /ui?is_done=false", tt.name, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestUpdateKeepsTheFilters(t *testing.T) {
+	r := newRouter()
+	cookie := csrfCookieFrom(t, r)
+
+	tests := []struct {
+		back     string
+		location string
+	}{
+		{"/ui?is_done=false&q=milk", "/ui?is_done=false&q=milk"},
+		{"", "/ui"},
+		{"https://evil.example.com/ui?q=milk", "/ui"},
+	}
+
+	for _, tt := range tests {
+		form := url.Values{
+			csrfCookie:    {cookie.Value},
+			"back":        {tt.back},
+			"title":       {"Buy milk"},
+			"description": {"Two liters, semi-skimmed"},
+		}
+
+		w := post(r, "/ui/todos/1", cookie, form)
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != tt.location {
+			t.Errorf("back %q: %d to %q, want %d to %q", tt.back, w.Code, w.Header().Get("Location"), http.StatusSeeOther, tt.location)
+		}
+	}
+}
+
+func TestEditCarriesTheFilters(t *testing.T) {
+	r := newRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui?is_done=false", nil))
+	link := `href="/ui/todos/1/edit?back=%2fui%3fis_done%3dfalse"`
+	if !strings.Contains(w.Body.String(), link) {
+		t.Fatalf("list page has no %s", link)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/todos/1/edit?back=%2fui%3fis_done%3dfalse", nil))
+	for _, want := range []string{`name="back" value="/ui?is_done=false"`, `<a href="/ui?is_done=false">cancel</a>`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("edit page has no %s", want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package web
+
+const layoutTemplate = `{{define "layout"}}<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · todo</title>
+<link rel="stylesheet" href="/ui/static/app.css">
+<script src="/ui/static/app.js" defer></script>
+</head>
+<body>
+<header><a href="/ui">todo</a></header>
+<main id="main">
+{{if .Error}}<p class="error" role="alert">{{.Error}}</p>{{end}}
+{{template "content" .}}
+</main>
+</body>
+</html>{{end}}`
+
+const listTemplate = `{{define "content"}}
+<form class="filters" method="get" action="/ui">
+  <input type="search" name="q" value="{{.Query}}" placeholder="search title">
+  <label>done
+    <select name="is_done">
+      <option value="" {{if eq .IsDone ""}}selected{{end}}>any</option>
+      <option value="false" {{if eq .IsDone "false"}}selected{{end}}>open</option>
+      <option value="true" {{if eq .IsDone "true"}}selected{{end}}>done</option>
+    </select>
+  </label>
+  <label>favorite
+    <select name="is_favorite">
+      <option value="" {{if eq .IsFavorite ""}}selected{{end}}>any</option>
+      <option value="true" {{if eq .IsFavorite "true"}}selected{{end}}>yes</option>
+      <option value="false" {{if eq .IsFavorite "false"}}selected{{end}}>no</option>
+    </select>
+  </label>
+  <button type="submit">filter</button>
+</form>
+
+<ul class="todos">
+{{range .Todos}}
+  <li class="todo{{if .IsDone}} done{{end}}">
+    <form method="post" action="/ui/todos/{{.ID}}/done" data-enhance>
+      <input type="hidden" name="csrf_token" value="{{$.CSRFToken}}">
+      <input type="hidden" name="back" value="{{$.Back}}">
+      <input type="hidden" name="is_done" value="{{if .IsDone}}false{{else}}true{{end}}">
+      <button type="submit" title="toggle done">{{if .IsDone}}☑{{else}}☐{{end}}</button>
+    </form>
+    <form method="post" action="/ui/todos/{{.ID}}/favorite" data-enhance>
+      <input type="hidden" name="csrf_token" value="{{$.CSRFToken}}">
+      <input type="hidden" name="back" value="{{$.Back}}">
+      <input type="hidden" name="is_favorite" value="{{if .IsFavorite}}false{{else}}true{{end}}">
+      <button type="submit" title="toggle favorite">{{if .IsFavorite}}★{{else}}☆{{end}}</button>
+    </form>
+    <div class="body">
+      <a class="title" href="/ui/todos/{{.ID}}/edit{{if ne $.Back "/ui"}}?back={{$.Back}}{{end}}">{{.Title}}</a>
+      <p>{{.Description}}</p>
+    </div>
+    <form method="post" action="/ui/todos/{{.ID}}/delete" data-enhance data-confirm="Delete this todo?">
+      <input type="hidden" name="csrf_token" value="{{$.CSRFToken}}">
+      <input type="hidden" name="back" value="{{$.Back}}">
+      <button type="submit" title="delete">✕</button>
+    </form>
+  </li>
+{{else}}
+  <li class="empty">No todos.</li>
+{{end}}
+</ul>
+
+<form class="create" method="post" action="/ui/todos">
+  <h2>New todo</h2>
+  <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+  <input type="hidden" name="back" value="{{.Back}}">
+  <label>Title <input name="title" value="{{.Form.Title}}" required minlength="3" maxlength="100"></label>
+  <label>Description <textarea name="description" required minlength="10">{{.Form.Description}}</textarea></label>
+  <button type="submit">add</button>
+</form>
+{{end}}`
+
+const editTemplate = `{{define "content"}}
+<form class="edit" method="post" action="/ui/todos/{{.Todo.ID}}">
+  <h2>Edit todo #{{.Todo.ID}}</h2>
+  <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+  <input type="hidden" name="back" value="{{.Back}}">
+  <label>Title <input name="title" value="{{.Todo.Title}}" required minlength="3" maxlength="100"></label>
+  <label>Description <textarea name="description" required minlength="10">{{.Todo.Description}}</textarea></label>
+  <label><input type="checkbox" name="is_done" value="true" {{if .Todo.IsDone}}checked{{end}}> done</label>
+  <label><input type="checkbox" name="is_favorite" value="true" {{if .Todo.IsFavorite}}checked{{end}}> favorite</label>
+  <button type="submit">save</button>
+  <a href="{{.Back}}">cancel</a>
+</form>
+{{end}}`
+
+const appCSS = `body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 0 auto; padding: 1rem; color: #222; }
+header a { font-weight: bold; font-size: 1.4rem; text-decoration: none; color: inherit; }
+.error { background: #fdecea; border: 1px solid #f5c2c0; padding: .5rem; }
+.filters, .create, .edit { display: flex; flex-wrap: wrap; gap: .5rem; align-items: end; margin: 1rem 0; }
+.create, .edit { flex-direction: column; align-items: stretch; }
+.todos { list-style: none; padding: 0; }
+.todo { display: flex; gap: .5rem; align-items: start; border-bottom: 1px solid #eee; padding: .5rem 0; }
+.todo .body { flex: 1; }
+.todo .body p { margin: .25rem 0 0; color: #555; }
+.todo.done .title { text-decoration: line-through; color: #888; }
+.todo button { background: none; border: none; font-size: 1.2rem; cursor: pointer; }
+textarea { min-height: 4rem; }
+`
+
+// appJS submits the enhanced forms in the background and swaps in the
+// re-rendered list. Without JavaScript the forms post and redirect.
+const appJS = `document.addEventListener("submit", function (e) {
+  var form = e.target;
+  if (!form.hasAttribute("data-enhance")) return;
+  var question = form.getAttribute("data-confirm");
+  if (question && !window.confirm(question)) { e.preventDefault(); return; }
+  e.preventDefault();
+  fetch(form.action, {
+    method: "POST",
+    body: new URLSearchParams(new FormData(form)),
+    credentials: "same-origin"
+  }).then(function (resp) {
+    return resp.text();
+  }).then(function (html) {
+    var doc = new DOMParser().parseFromString(html, "text/html");
+    var main = doc.getElementById("main");
+    if (main) document.getElementById("main").replaceWith(main);
+  }).catch(function () { form.submit(); });
+});
+`
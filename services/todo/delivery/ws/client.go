@@ -0,0 +1,94 @@
+package ws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxMessageSize = 64 * 1024
+	sendBuffer     = 64
+)
+
+// client is a single websocket connection. Outgoing messages are queued on
+// send; a client that lets the queue fill up is disconnected instead of
+// slowing down everyone else.
+type client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	// name is the presence name the client chose; it is not verified.
+	name      string
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	code      int
+	reason    string
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, name string) *client {
+	return &client{
+		hub:  hub,
+		conn: conn,
+		name: name,
+		send: make(chan []byte, sendBuffer),
+		done: make(chan struct{}),
+	}
+}
+
+func (c *client) enqueue(content []byte) {
+	select {
+	case <-c.done:
+	case c.send <- content:
+	default:
+		c.close("slow consumer")
+	}
+}
+
+func (c *client) close(reason string) {
+	code := websocket.CloseNormalClosure
+	if reason != "" {
+		code = websocket.ClosePolicyViolation
+	}
+	c.closeWith(code, reason)
+}
+
+func (c *client) closeWith(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.code = code
+		c.reason = reason
+		close(c.done)
+	})
+}
+
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case content := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, content); err != nil {
+				c.close("")
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close("")
+				return
+			}
+		case <-c.done:
+			_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(c.code, c.reason), time.Now().Add(writeWait))
+			return
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/message"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeCreate      = "create"
+	TypeUpdate      = "update"
+	TypeDone        = "done"
+	TypeFavorite    = "favorite"
+	TypeDelete      = "delete"
+
+	TypeResult   = "result"
+	TypeError    = "error"
+	TypeEvent    = "event"
+	TypePresence = "presence"
+)
+
+// anonymous is the presence name of a client that did not choose one.
+const anonymous = "anonymous"
+
+// incoming is a message sent by the client. ID is echoed back on the reply
+// so the client can correlate requests and responses.
+type incoming struct {
+	ID    string          `json:"id"`
+	Type  string          `json:"type"`
+	Topic string          `json:"topic"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type outgoing struct {
+	ID    string                  `json:"id,omitempty"`
+	Type  string                  `json:"type"`
+	Topic string                  `json:"topic,omitempty"`
+	Data  interface{}             `json:"data,omitempty"`
+	Error *response.ErrorResponse `json:"error,omitempty"`
+}
+
+// presence lists the names the subscribers of a topic chose for
+// themselves. They are not authenticated, so two clients may share a name
+// and anyone can claim any name.
+type presence struct {
+	Names []string `json:"names"`
+}
+
+type idRequest struct {
+	ID int `json:"id"`
+}
+
+type updateRequest struct {
+	ID int `json:"id"`
+	todo.UpdateRequest
+}
+
+type doneRequest struct {
+	ID int `json:"id"`
+	todo.DoneRequest
+}
+
+type favoriteRequest struct {
+	ID int `json:"id"`
+	todo.FavoriteRequest
+}
+
+type TodoHandler struct {
+	TodoService service.Service
+	hub         *Hub
+	upgrader    websocket.Upgrader
+}
+
+// NewTodoHandler serves the channel at /ws. Browsers send no CORS
+// preflight before a websocket upgrade, so checkOrigin decides which pages
+// may open it with the user's credentials; nil allows only same-origin
+// pages.
+func NewTodoHandler(r *mux.Router, todoService service.Service, hub *Hub, checkOrigin func(*http.Request) bool) {
+	handler := &TodoHandler{
+		TodoService: todoService,
+		hub:         hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			CheckOrigin:     checkOrigin,
+		},
+	}
+
+	r.HandleFunc("/ws", handler.Serve).Methods(http.MethodGet)
+}
+
+func (c *TodoHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = anonymous
+	}
+
+	conn, err := c.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	cl := newClient(c.hub, conn, name)
+	c.hub.join(cl)
+	go cl.writePump()
+
+	c.readPump(r.Context(), cl)
+}
+
+func (c *TodoHandler) readPump(ctx context.Context, cl *client) {
+	defer func() {
+		c.hub.remove(cl)
+		cl.close("")
+	}()
+
+	cl.conn.SetReadLimit(maxMessageSize)
+	_ = cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cl.conn.SetPongHandler(func(string) error {
+		return cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		msg := new(incoming)
+		if err := cl.conn.ReadJSON(msg); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				c.reply(cl, msg, nil, message.NewErrorMessage(0, "invalid json message"))
+				continue
+			}
+			return
+		}
+
+		data, errMessage := c.handle(ctx, cl, msg)
+		c.reply(cl, msg, data, errMessage)
+	}
+}
+
+func (c *TodoHandler) reply(cl *client, msg *incoming, data interface{}, errMessage *message.ErrorResponse) {
+	out := outgoing{
+		ID:    msg.ID,
+		Type:  TypeResult,
+		Topic: msg.Topic,
+		Data:  data,
+	}
+
+	if errMessage != nil {
+		out.Type = TypeError
+		out.Data = nil
+		out.Error = &response.ErrorResponse{
+			Code:    errMessage.Code(),
+			Message: errMessage.Message(),
+		}
+	}
+
+	content, err := json.Marshal(out)
+	if err != nil {
+		return
+	}
+
+	cl.enqueue(content)
+}
+
+func (c *TodoHandler) handle(ctx context.Context, cl *client, msg *incoming) (interface{}, *message.ErrorResponse) {
+	switch msg.Type {
+	case TypeSubscribe:
+		if !validTopic(msg.Topic) {
+			return nil, message.NewErrorMessage(0, "topic should be todos or todo:{id}")
+		}
+
+		return presence{Names: c.hub.subscribe(cl, msg.Topic)}, nil
+	case TypeUnsubscribe:
+		c.hub.unsubscribe(cl, msg.Topic)
+		return nil, nil
+	case TypeCreate:
+		formData := new(todo.CreateRequest)
+		if err := json.Unmarshal(msg.Data, formData); err != nil {
+			return nil, message.NewErrorMessage(0, "invalid json body")
+		}
+
+		resp, err := c.TodoService.Create(ctx, formData)
+		if err != nil {
+			return nil, message.NewErrorMessage(0, err.Error())
+		}
+
+		return resp, nil
+	case TypeUpdate:
+		formData := new(updateRequest)
+		if err := json.Unmarshal(msg.Data, formData); err != nil {
+			return nil, message.NewErrorMessage(0, "invalid json body")
+		}
+
+		if formData.ID <= 0 {
+			return nil, message.NewErrorMessage(0, "id should be a positive number")
+		}
+
+		resp, err := c.TodoService.UpdateData(ctx, formData.ID, &formData.UpdateRequest)
+		if err != nil {
+			return nil, message.NewErrorMessage(0, err.Error())
+		}
+
+		return resp, nil
+	case TypeDone:
+		formData := new(doneRequest)
+		if err := json.Unmarshal(msg.Data, formData); err != nil {
+			return nil, message.NewErrorMessage(0, "invalid json body")
+		}
+
+		if formData.ID <= 0 {
+			return nil, message.NewErrorMessage(0, "id should be a positive number")
+		}
+
+		if err := c.TodoService.MarkAsDone(ctx, formData.ID, &formData.DoneRequest); err != nil {
+			return nil, message.NewErrorMessage(0, err.Error())
+		}
+
+		return nil, nil
+	case TypeFavorite:
+		formData := new(favoriteRequest)
+		if err := json.Unmarshal(msg.Data, formData); err != nil {
+			return nil, message.NewErrorMessage(0, "invalid json body")
+		}
+
+		if formData.ID <= 0 {
+			return nil, message.NewErrorMessage(0, "id should be a positive number")
+		}
+
+		if err := c.TodoService.MarkAsFavorite(ctx, formData.ID, &formData.FavoriteRequest); err != nil {
+			return nil, message.NewErrorMessage(0, err.Error())
+		}
+
+		return nil, nil
+	case TypeDelete:
+		formData := new(idRequest)
+		if err := json.Unmarshal(msg.Data, formData); err != nil {
+			return nil, message.NewErrorMessage(0, "invalid json body")
+		}
+
+		if formData.ID <= 0 {
+			return nil, message.NewErrorMessage(0, "id should be a positive number")
+		}
+
+		if err := c.TodoService.DeleteByID(ctx, formData.ID); err != nil {
+			return nil, message.NewErrorMessage(0, err.Error())
+		}
+
+		return nil, nil
+	}
+
+	return nil, message.NewErrorMessage(0, "unknown message type")
+}
@@ -0,0 +1,282 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/cors"
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/jinzhu/gorm"
+)
+
+// fakeService creates todos with increasing ids and leaves the rest of
+// service.Service unimplemented.
+type fakeService struct {
+	service.Service
+	lastID uint
+}
+
+func (c *fakeService) Create(ctx context.Context, form *todo.CreateRequest) (*todo.CreateResponse, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.lastID++
+	return &todo.CreateResponse{Model: gorm.Model{ID: c.lastID}, Title: form.Title, Description: form.Description}, nil
+}
+
+// reply is outgoing as a client decodes it.
+type reply struct {
+	ID    string                  `json:"id"`
+	Type  string                  `json:"type"`
+	Topic string                  `json:"topic"`
+	Data  json.RawMessage         `json:"data"`
+	Error *response.ErrorResponse `json:"error"`
+}
+
+// serve runs a hub fed by broker and the handler in front of it. The
+// returned func stops both.
+func serve(t *testing.T, todoService service.Service, broker *event.Broker) (string, func()) {
+	hub := NewHub(broker)
+	go hub.Run()
+	r := mux.NewRouter()
+	NewTodoHandler(r, todoService, hub, nil)
+	server := httptest.NewServer(r)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws", func() {
+		broker.Close()
+		server.Close()
+	}
+}
+
+func dial(t *testing.T, target, name string) *websocket.Conn {
+	if name != "" {
+		target += "?name=" + url.QueryEscape(name)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg string) {
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// next returns the next message of type typ, skipping the others.
+func next(t *testing.T, conn *websocket.Conn, typ string) reply {
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg reply
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for a %s message: %s", typ, err)
+		}
+		if msg.Type == typ {
+			return msg
+		}
+	}
+}
+
+// subscribe subscribes conn to topic and returns the names in the reply.
+// The presence broadcast the subscription causes comes first and is
+// skipped.
+func subscribe(t *testing.T, conn *websocket.Conn, topic string) []string {
+	send(t, conn, `{"id":"sub","type":"subscribe","topic":"`+topic+`"}`)
+	msg := next(t, conn, TypeResult)
+
+	var data presence
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data.Names
+}
+
+func eventID(t *testing.T, msg reply) uint {
+	var e event.Event
+	if err := json.Unmarshal(msg.Data, &e); err != nil {
+		t.Fatal(err)
+	}
+	return e.Todo.ID
+}
+
+func TestUpgradeChecksOrigin(t *testing.T) {
+	policy, err := cors.New(cors.Policy{AllowedOrigins: []string{"https://app.example.com"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hub := NewHub(event.NewBroker())
+	go hub.Run()
+	r := mux.NewRouter()
+	NewTodoHandler(r, nil, hub, policy.CheckOrigin)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	target := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	tests := []struct {
+		origin string
+		status int
+	}{
+		{"", http.StatusSwitchingProtocols},
+		{server.URL, http.StatusSwitchingProtocols},
+		{"https://app.example.com", http.StatusSwitchingProtocols},
+		{"https://evil.example.com", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		header := http.Header{}
+		if test.origin != "" {
+			header.Set("Origin", test.origin)
+		}
+
+		conn, resp, err := websocket.DefaultDialer.Dial(target, header)
+		if conn != nil {
+			conn.Close()
+		}
+		if resp == nil {
+			t.Fatalf("origin %q: %s", test.origin, err)
+		}
+		if resp.StatusCode != test.status {
+			t.Errorf("origin %q: status = %d, want %d", test.origin, resp.StatusCode, test.status)
+		}
+	}
+}
+
+func TestRepliesCarryTheRequestID(t *testing.T) {
+	target, stop := serve(t, &fakeService{}, event.NewBroker())
+	defer stop()
+	conn := dial(t, target, "")
+	defer conn.Close()
+
+	send(t, conn, `{"id":"req-1","type":"create","data":{"title":"Buy milk","description":"Two liters, semi-skimmed"}}`)
+	msg := next(t, conn, TypeResult)
+	if msg.ID != "req-1" {
+		t.Errorf("result id = %q, want req-1", msg.ID)
+	}
+	var created todo.CreateResponse
+	if err := json.Unmarshal(msg.Data, &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 1 || created.Title != "Buy milk" {
+		t.Errorf("result = %+v, want todo 1", created)
+	}
+
+	send(t, conn, `{"id":"req-2","type":"delete","data":{"id":0}}`)
+	msg = next(t, conn, TypeError)
+	if msg.ID != "req-2" || msg.Error == nil || msg.Error.Message != "id should be a positive number" {
+		t.Errorf("error reply = %+v, want req-2 with the id error", msg)
+	}
+}
+
+func TestEventsReachTopicSubscribers(t *testing.T) {
+	broker := event.NewBroker()
+	target, stop := serve(t, &fakeService{}, broker)
+	defer stop()
+
+	all := dial(t, target, "")
+	defer all.Close()
+	first := dial(t, target, "")
+	defer first.Close()
+	second := dial(t, target, "")
+	defer second.Close()
+	subscribe(t, all, TopicTodos)
+	subscribe(t, first, "todo:1")
+	subscribe(t, second, "todo:2")
+
+	// The hub subscribes to the broker in its own goroutine, so keep
+	// publishing until the clients have seen what they wait for.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			broker.Publish(event.Event{Type: event.Updated, Todo: todo.ViewResponse{Model: gorm.Model{ID: 1}}})
+			broker.Publish(event.Event{Type: event.Updated, Todo: todo.ViewResponse{Model: gorm.Model{ID: 2}}})
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	seen := make(map[uint]bool)
+	for len(seen) < 2 {
+		msg := next(t, all, TypeEvent)
+		if msg.Topic != TopicTodos {
+			t.Fatalf("event topic = %q, want %s", msg.Topic, TopicTodos)
+		}
+		seen[eventID(t, msg)] = true
+	}
+
+	for i := 0; i < 3; i++ {
+		if msg := next(t, first, TypeEvent); msg.Topic != "todo:1" || eventID(t, msg) != 1 {
+			t.Fatalf("todo:1 subscriber got %s for todo %d", msg.Topic, eventID(t, msg))
+		}
+		if msg := next(t, second, TypeEvent); msg.Topic != "todo:2" || eventID(t, msg) != 2 {
+			t.Fatalf("todo:2 subscriber got %s for todo %d", msg.Topic, eventID(t, msg))
+		}
+	}
+}
+
+func TestPresenceJoinAndLeave(t *testing.T) {
+	target, stop := serve(t, &fakeService{}, event.NewBroker())
+	defer stop()
+
+	ana := dial(t, target, "ana")
+	defer ana.Close()
+	if names := subscribe(t, ana, "todo:1"); !reflect.DeepEqual(names, []string{"ana"}) {
+		t.Errorf("ana joins: names = %v, want [ana]", names)
+	}
+
+	budi := dial(t, target, "budi")
+	if names := subscribe(t, budi, "todo:1"); !reflect.DeepEqual(names, []string{"ana", "budi"}) {
+		t.Errorf("budi joins: names = %v, want [ana budi]", names)
+	}
+	if msg := next(t, ana, TypePresence); string(msg.Data) != `{"names":["ana","budi"]}` {
+		t.Errorf("ana sees budi join: presence = %s", msg.Data)
+	}
+
+	budi.Close()
+	if msg := next(t, ana, TypePresence); string(msg.Data) != `{"names":["ana"]}` {
+		t.Errorf("ana sees budi leave: presence = %s", msg.Data)
+	}
+}
+
+func TestSlowConsumerIsDisconnected(t *testing.T) {
+	hub := NewHub(event.NewBroker())
+	slow := newClient(hub, nil, anonymous)
+	hub.join(slow)
+	hub.subscribe(slow, TopicTodos)
+
+	// Nothing drains send, as if the client stopped reading.
+	for i := 0; i < sendBuffer; i++ {
+		hub.broadcast(TopicTodos, outgoing{Type: TypeEvent})
+	}
+
+	select {
+	case <-slow.done:
+	default:
+		t.Fatal("client with a full send queue was not closed")
+	}
+	if slow.code != websocket.ClosePolicyViolation || slow.reason != "slow consumer" {
+		t.Errorf("close = %d %q, want %d \"slow consumer\"", slow.code, slow.reason, websocket.ClosePolicyViolation)
+	}
+}
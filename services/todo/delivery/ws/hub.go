@@ -0,0 +1,186 @@
+package ws
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/gorilla/websocket"
+)
+
+const TopicTodos = "todos"
+
+// Hub keeps track of topic subscriptions and relays broker events to the
+// clients subscribed to them.
+type Hub struct {
+	mu      sync.Mutex
+	topics  map[string]map[*client]struct{}
+	clients map[*client]struct{}
+	closed  bool
+	broker  *event.Broker
+}
+
+func NewHub(broker *event.Broker) *Hub {
+	return &Hub{
+		topics:  make(map[string]map[*client]struct{}),
+		clients: make(map[*client]struct{}),
+		broker:  broker,
+	}
+}
+
+// Run relays broker events until the broker subscription is closed, then
+// tells every client the server is going away.
+func (c *Hub) Run() {
+	defer c.closeAll()
+
+	events := c.broker.Subscribe(256)
+	for e := range events {
+		msg := outgoing{
+			Type: TypeEvent,
+			Data: e,
+		}
+
+		c.broadcast(TopicTodos, msg)
+		c.broadcast(todoTopic(int(e.Todo.ID)), msg)
+	}
+}
+
+// join tracks cl so it is closed when the hub stops. A client connecting
+// after that is closed right away.
+func (c *Hub) join(cl *client) {
+	c.mu.Lock()
+	closed := c.closed
+	if !closed {
+		c.clients[cl] = struct{}{}
+	}
+	c.mu.Unlock()
+
+	if closed {
+		cl.closeWith(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+func (c *Hub) closeAll() {
+	c.mu.Lock()
+	c.closed = true
+	clients := make([]*client, 0, len(c.clients))
+	for cl := range c.clients {
+		clients = append(clients, cl)
+	}
+	c.mu.Unlock()
+
+	for _, cl := range clients {
+		cl.closeWith(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+func (c *Hub) subscribe(cl *client, topic string) []string {
+	c.mu.Lock()
+	subscribers, ok := c.topics[topic]
+	if !ok {
+		subscribers = make(map[*client]struct{})
+		c.topics[topic] = subscribers
+	}
+	subscribers[cl] = struct{}{}
+	c.mu.Unlock()
+
+	return c.announce(topic)
+}
+
+func (c *Hub) unsubscribe(cl *client, topic string) {
+	c.mu.Lock()
+	subscribers, ok := c.topics[topic]
+	if ok {
+		delete(subscribers, cl)
+		if len(subscribers) == 0 {
+			delete(c.topics, topic)
+		}
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.announce(topic)
+	}
+}
+
+func (c *Hub) remove(cl *client) {
+	c.mu.Lock()
+	delete(c.clients, cl)
+	topics := make([]string, 0)
+	for topic, subscribers := range c.topics {
+		if _, ok := subscribers[cl]; ok {
+			topics = append(topics, topic)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, topic := range topics {
+		c.unsubscribe(cl, topic)
+	}
+}
+
+// announce sends the current presence of a topic to its subscribers and
+// returns it.
+func (c *Hub) announce(topic string) []string {
+	c.mu.Lock()
+	seen := make(map[string]struct{})
+	names := make([]string, 0)
+	for cl := range c.topics[topic] {
+		if _, ok := seen[cl.name]; ok {
+			continue
+		}
+		seen[cl.name] = struct{}{}
+		names = append(names, cl.name)
+	}
+	c.mu.Unlock()
+
+	sort.Strings(names)
+	c.broadcast(topic, outgoing{
+		Type:  TypePresence,
+		Topic: topic,
+		Data:  presence{Names: names},
+	})
+
+	return names
+}
+
+func (c *Hub) broadcast(topic string, msg outgoing) {
+	if msg.Topic == "" {
+		msg.Topic = topic
+	}
+
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	subscribers := make([]*client, 0, len(c.topics[topic]))
+	for cl := range c.topics[topic] {
+		subscribers = append(subscribers, cl)
+	}
+	c.mu.Unlock()
+
+	for _, cl := range subscribers {
+		cl.enqueue(content)
+	}
+}
+
+func todoTopic(id int) string {
+	return "todo:" + strconv.Itoa(id)
+}
+
+func validTopic(topic string) bool {
+	if topic == TopicTodos {
+		return true
+	}
+
+	if len(topic) <= len("todo:") || topic[:len("todo:")] != "todo:" {
+		return false
+	}
+
+	id, err := strconv.Atoi(topic[len("todo:"):])
+	return err == nil && id > 0
+}
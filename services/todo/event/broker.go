@@ -0,0 +1,92 @@
+package event
+
+import (
+	"sync"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+type Type string
+
+const (
+	Created Type = "created"
+	Updated Type = "updated"
+	Deleted Type = "deleted"
+)
+
+type Event struct {
+	Type Type              `json:"type"`
+	Todo todo.ViewResponse `json:"todo"`
+}
+
+// Broker fans out todo change events to every subscriber. Publish never
+// blocks: a subscriber whose buffer is full misses the event.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+func (c *Broker) Subscribe(buffer int) chan Event {
+	ch := make(chan Event, buffer)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		close(ch)
+		return ch
+	}
+	c.subscribers[ch] = struct{}{}
+
+	return ch
+}
+
+func (c *Broker) Unsubscribe(ch chan Event) {
+	c.mu.Lock()
+	if _, ok := c.subscribers[ch]; ok {
+		delete(c.subscribers, ch)
+		close(ch)
+	}
+	c.mu.Unlock()
+}
+
+func (c *Broker) Publish(e Event) {
+	if c == nil {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for ch := range c.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Close closes every subscription, which ends the streams fed by them, and
+// makes later subscriptions closed from the start. Events published after
+// Close are dropped.
+func (c *Broker) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+
+	for ch := range c.subscribers {
+		delete(c.subscribers, ch)
+		close(ch)
+	}
+}
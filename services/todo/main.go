@@ -1,45 +1,83 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"github.com/ardiantirta/todo-crud/common/cors"
+	"github.com/ardiantirta/todo-crud/common/health"
 	"github.com/ardiantirta/todo-crud/common/http/request"
-	"github.com/ardiantirta/todo-crud/common/http/response"
-	"log"
+	"github.com/ardiantirta/todo-crud/common/logging"
+	"github.com/ardiantirta/todo-crud/common/metrics"
+	"github.com/ardiantirta/todo-crud/common/secure"
+	"github.com/ardiantirta/todo-crud/common/tracing"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
-	"github.com/gorilla/handlers"
-	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
+	"google.golang.org/grpc"
 
+	todoGrpc "github.com/ardiantirta/todo-crud/services/todo/delivery/grpc"
 	todoHttp "github.com/ardiantirta/todo-crud/services/todo/delivery/http"
+	todoWs "github.com/ardiantirta/todo-crud/services/todo/delivery/ws"
+	"github.com/ardiantirta/todo-crud/services/todo/config"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/migration"
 	_todoRepository "github.com/ardiantirta/todo-crud/services/todo/repository"
 	_todoService "github.com/ardiantirta/todo-crud/services/todo/service"
 )
 
-func init() {
-	viper.SetConfigFile("./config/config.json")
-	if err := viper.ReadInConfig(); err != nil {
-		panic("err")
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+
+	cfg, args, err := config.Load(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		logrus.Error(err)
+		os.Exit(1)
 	}
 
-	if viper.GetBool("debug") {
+	// Printing the configuration must work even when it is invalid, to
+	// see where a bad value came from.
+	if len(args) > 0 && args[0] == "config" {
+		if err := runConfig(cfg, args[1:]); err != nil {
+			logrus.Error(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		logrus.Error(err)
+		os.Exit(1)
+	}
+
+	if cfg.Debug {
+		logrus.SetLevel(logrus.DebugLevel)
 		fmt.Println("service run on debug mode")
 	}
-}
 
-func main() {
-	dbHost := viper.GetString("database.host")
-	dbPort := viper.GetString("database.port")
-	dbUser := viper.GetString("database.user")
-	dbPass := viper.GetString("database.pass")
-	dbName := viper.GetString("database.name")
+	// Settings that are safe to change while running are reloaded on
+	// SIGHUP or when the config file changes.
+	reloader := config.NewReloader(cfg, os.Args[1:])
+	reloader.OnReload(func(cfg *config.Config) {
+		if cfg.Debug {
+			logrus.SetLevel(logrus.DebugLevel)
+		} else {
+			logrus.SetLevel(logrus.InfoLevel)
+		}
+	})
 
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", dbUser, dbPass, dbHost, dbPort, dbName)
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", url.PathEscape(cfg.Database.User), url.PathEscape(cfg.Database.Pass), cfg.Database.Host, cfg.Database.Port, url.PathEscape(cfg.Database.Name))
 	val := url.Values{}
 	val.Add("sslmode", "disable")
 	dsn := fmt.Sprintf("%s?%s", connStr, val.Encode())
@@ -54,32 +92,207 @@ func main() {
 	}
 	fmt.Println("ping from db")
 
-	defer func() {
-		err := dbConn.Close()
+	// The repository logs database errors with the request they happened
+	// in; gorm's own log would repeat them without it.
+	dbConn.LogMode(false)
+
+	migrator := migration.NewMigrator(dbConn)
+
+	if len(args) > 0 {
+		err := runCommand(migrator, _todoRepository.NewTodoRepository(dbConn), args)
+		if closeErr := dbConn.Close(); closeErr != nil {
+			logrus.Error(closeErr)
+		}
 		if err != nil {
-			log.Fatal(err)
+			logrus.Error(err)
+			os.Exit(1)
 		}
+		return
+	}
+
+	if err := migrator.Check(context.Background()); err != nil {
+		logrus.Error(err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: "todo-crud",
+	})
+	if err != nil {
+		logrus.Error(err)
+		os.Exit(1)
+	}
+
+	healthChecks := health.New(cfg.Health.Timeout, cfg.Health.Cache)
+	reloader.OnReload(func(cfg *config.Config) {
+		healthChecks.SetTimeouts(cfg.Health.Timeout, cfg.Health.Cache)
+	})
+	healthChecks.Add("database", health.DB(dbConn.DB()))
+	healthChecks.Add("migrations", health.CheckerFunc(migrator.Health))
+
+	broker := event.NewBroker()
+	hub := todoWs.NewHub(broker)
+	hubDone := make(chan struct{})
+	go func() {
+		hub.Run()
+		close(hubDone)
 	}()
 
-	dbConn.Debug().AutoMigrate(
-		&_todoRepository.Todo{},
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		metrics.NewDBStatsCollector(dbConn.DB(), "todo"),
 	)
+	httpMetrics := metrics.NewHTTP(registry, "todo")
 
-	r := mux.NewRouter()
+	timeoutRepository := &_todoRepository.TimeoutRepository{
+		Next:     _todoRepository.NewTodoRepository(dbConn, tracing.RegisterCallbacks),
+		Default:  cfg.Database.Timeout,
+		Timeouts: cfg.Database.OperationTimeouts,
+	}
+	reloader.OnReload(func(cfg *config.Config) {
+		timeoutRepository.SetTimeouts(cfg.Database.Timeout, cfg.Database.OperationTimeouts)
+	})
+	todoRepository := _todoRepository.NewInstrumentedRepository(_todoRepository.NewTracingRepository(timeoutRepository), registry)
+	registry.MustRegister(_todoRepository.NewTodoCollector(todoRepository))
+	todoService := _todoService.NewLoggingService(_todoService.NewTracingService(_todoService.NewTodoService(todoRepository, broker)))
 
-	defaultHandler := request.NewDefaultHandler(response.NewDefaultJSONResponder())
-	r.Handle("/", handlers.LoggingHandler(os.Stdout, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defaultHandler.Index(w, r)
-		return
-	}))).Methods(http.MethodGet)
+	// Metrics go on their own port when one is configured, so they can
+	// stay off the public listener.
+	metricsHandler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+	var metricsServer *http.Server
+	if metricsAddress := cfg.Metrics.Address; metricsAddress != "" {
+		lis, err := net.Listen("tcp", metricsAddress)
+		if err != nil {
+			logrus.Error(err)
+			os.Exit(1)
+		}
+
+		internal := http.NewServeMux()
+		internal.Handle("/metrics", metricsHandler)
+		metricsServer = newHTTPServer(metricsAddress, cfg.Server, internal)
+		go func() {
+			if err := metricsServer.Serve(lis); err != nil && err != http.ErrServerClosed {
+				logrus.Error(err)
+			}
+		}()
+		metricsHandler = nil
+	}
+
+	corsPolicy, err := cors.New(cfg.CORS.Policies())
+	if err != nil {
+		logrus.Error(err)
+		os.Exit(1)
+	}
+	reloader.OnReload(func(cfg *config.Config) {
+		if err := corsPolicy.Update(cfg.CORS.Policies()); err != nil {
+			logrus.Error(err)
+		}
+	})
+
+	transferService := _todoService.NewTransferService(todoRepository, todoService)
+	openAPIDocument := todoHttp.NewOpenAPIDocument()
+	r, err := newRouter(services{
+		todo:          todoService,
+		transfer:      transferService,
+		markdown:      _todoService.NewMarkdownService(todoService),
+		sync:          _todoService.NewSyncService(todoRepository, todoService, cfg.Sync.Conflict),
+		broker:        broker,
+		hub:           hub,
+		health:        healthChecks,
+		calendarToken: cfg.Calendar.Token,
+		checkOrigin:   corsPolicy.CheckOrigin,
+		metrics:       metricsHandler,
+	}, openAPIDocument)
+	if err != nil {
+		logrus.Error(err)
+		os.Exit(1)
+	}
+	r.Use(logging.Route)
+	r.Use(tracing.Route)
+	r.Use(httpMetrics.Middleware)
+	r.Use(openAPIDocument.ValidateRequest)
 
-	todoRepository := _todoRepository.NewTodoRepository(dbConn)
-	todoService := _todoService.NewTodoService(todoRepository)
-	todoHttp.NewTodoHandler(r, todoService)
+	var grpcServer *grpc.Server
+	if grpcAddress := cfg.GRPC.Address; grpcAddress != "" {
+		lis, err := net.Listen("tcp", grpcAddress)
+		if err != nil {
+			logrus.Error(err)
+			os.Exit(1)
+		}
 
-	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
-	originsOk := handlers.AllowedOrigins([]string{"*"})
-	methodsOk := handlers.AllowedMethods([]string{"GET", "POST", "PUT", "HEAD", "OPTIONS", "PATCH", "DELETE"})
+		grpcServer = grpc.NewServer()
+		todoGrpc.NewTodoServer(grpcServer, todoService, broker)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				logrus.Error(err)
+			}
+		}()
+	}
 
-	log.Fatal(http.ListenAndServe(viper.GetString("server.address"), handlers.CORS(headersOk, originsOk, methodsOk)(r)))
+	handler := secure.Middleware(secure.Config{
+		HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+		HSTSIncludeSubdomains: cfg.Security.HSTSIncludeSubdomains,
+		FrameOptions:          cfg.Security.FrameOptions,
+		ContentSecurityPolicy: cfg.Security.ContentSecurityPolicy,
+		ReferrerPolicy:        cfg.Security.ReferrerPolicy,
+	})(corsPolicy.Middleware(r))
+
+	server := newHTTPServer(cfg.Server.Address, cfg.Server, tracing.Middleware(logging.Middleware(logrus.StandardLogger())(request.LimitBodyFunc(func(r *http.Request) int64 { return bodyLimit(reloader.Current().Server, r) })(handler))))
+	watchDone := make(chan struct{})
+	go func() {
+		if err := reloader.Watch(watchDone); err != nil {
+			logrus.Error(err)
+		}
+	}()
+
+	serveErr := serve(server)
+	close(watchDone)
+
+	// Fail readiness first and give load balancers server.drain_delay to
+	// notice before the listener closes.
+	healthChecks.Drain()
+	if serveErr == nil {
+		time.Sleep(reloader.Current().Server.DrainDelay)
+	}
+
+	// Closing the broker ends the event streams, which would otherwise
+	// keep their requests open until the deadline.
+	steps := []shutdownStep{
+		{name: "events", stop: func(ctx context.Context) error {
+			broker.Close()
+			select {
+			case <-hubDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}},
+		{name: "http", stop: stopHTTP(server)},
+	}
+	if metricsServer != nil {
+		steps = append(steps, shutdownStep{name: "metrics", stop: stopHTTP(metricsServer)})
+	}
+	if grpcServer != nil {
+		steps = append(steps, shutdownStep{name: "grpc", stop: stopGRPC(grpcServer)})
+	}
+	steps = append(steps,
+		shutdownStep{name: "tracing", stop: func(ctx context.Context) error {
+			shutdownTracing()
+			return nil
+		}},
+		shutdownStep{name: "database", stop: func(ctx context.Context) error {
+			return dbConn.Close()
+		}},
+	)
+	shutdown(reloader.Current().Server.ShutdownTimeout, steps...)
+
+	if serveErr != nil {
+		logrus.Error(serveErr)
+		os.Exit(1)
+	}
 }
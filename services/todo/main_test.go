@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/health"
+	"github.com/ardiantirta/todo-crud/common/http/request"
+	"github.com/ardiantirta/todo-crud/services/todo/config"
+	todoHttp "github.com/ardiantirta/todo-crud/services/todo/delivery/http"
+	todoWs "github.com/ardiantirta/todo-crud/services/todo/delivery/ws"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+)
+
+func testServices(t *testing.T) services {
+	t.Helper()
+	broker := event.NewBroker()
+	return services{
+		broker:  broker,
+		hub:     todoWs.NewHub(broker),
+		health:  health.New(time.Second, 0),
+		metrics: http.NotFoundHandler(),
+	}
+}
+
+// TestRoutesAreDocumented fails when a route served by main has no
+// operation in the OpenAPI document.
+func TestRoutesAreDocumented(t *testing.T) {
+	doc := todoHttp.NewOpenAPIDocument()
+	r, err := newRouter(testServices(t), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doc.CheckRoutes(r); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckRoutesReportsGaps(t *testing.T) {
+	doc := todoHttp.NewOpenAPIDocument()
+	r, err := newRouter(testServices(t), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.HandleFunc("/undocumented", http.NotFound).Methods(http.MethodGet)
+	r.PathPrefix("/anything/").HandlerFunc(http.NotFound)
+
+	err = doc.CheckRoutes(r)
+	if err == nil {
+		t.Fatal("CheckRoutes passed with undocumented routes")
+	}
+	for _, want := range []string{"GET /undocumented", "* /anything/"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not list %q", err, want)
+		}
+	}
+}
+
+func TestImportsHaveTheirOwnBodyLimit(t *testing.T) {
+	cfg := config.Server{MaxBodyBytes: 10, MaxImportBytes: 100}
+	handler := request.LimitBodyFunc(func(r *http.Request) int64 { return bodyLimit(cfg, r) })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := strings.Repeat("x", 50)
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/todo", http.StatusRequestEntityTooLarge},
+		{"/todo/import", http.StatusOK},
+		{"/todo/import?format=csv", http.StatusOK},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, test.target, strings.NewReader(body)))
+		if w.Code != test.status {
+			t.Errorf("POST %s: status = %d, want %d", test.target, w.Code, test.status)
+		}
+	}
+}
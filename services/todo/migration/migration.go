@@ -0,0 +1,286 @@
+// Package migration versions the database schema. Migrations are Go
+// functions compiled into the binary; schema_migrations records which
+// ones were applied.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// lockKey identifies the Postgres advisory lock held while migrating.
+const lockKey = 0x746f646f
+
+// Migration is a single schema change. Up and Down run in a transaction
+// together with the schema_migrations update.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Exec returns a migration step that runs SQL statements in order.
+func Exec(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Status is a migration and when it was applied, if it was.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) *Migrator {
+	return &Migrator{
+		DB:         db,
+		Migrations: Migrations,
+	}
+}
+
+// Latest returns the version this build expects.
+func (c *Migrator) Latest() int {
+	if len(c.Migrations) == 0 {
+		return 0
+	}
+
+	return c.Migrations[len(c.Migrations)-1].Version
+}
+
+// Current returns the highest applied version, 0 for a new database.
+func (c *Migrator) Current(ctx context.Context) (int, error) {
+	applied, err := c.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+
+	return current, nil
+}
+
+// Check refuses a database whose schema is not the one this build
+// expects, either because migrations are pending or because a newer
+// build migrated it.
+func (c *Migrator) Check(ctx context.Context) error {
+	current, err := c.Current(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch latest := c.Latest(); {
+	case current < latest:
+		return fmt.Errorf("database schema is at version %d, this build expects %d: run `todo-server migrate up`", current, latest)
+	case current > latest:
+		return fmt.Errorf("database schema is at version %d, newer than the %d this build knows", current, latest)
+	}
+
+	return nil
+}
+
+// Health reports the schema version for the readiness probe and fails
+// when Check would.
+func (c *Migrator) Health(ctx context.Context) (string, error) {
+	current, err := c.Current(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("version %d of %d", current, c.Latest()), c.Check(ctx)
+}
+
+func (c *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := c.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]Status, 0, len(c.Migrations))
+	for _, m := range c.Migrations {
+		s := Status{Migration: m}
+		if row, ok := applied[m.Version]; ok {
+			s.AppliedAt = &row.AppliedAt
+		}
+		status = append(status, s)
+	}
+
+	return status, nil
+}
+
+// Up applies every pending migration.
+func (c *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return c.To(ctx, c.Latest())
+}
+
+// Down reverts the latest applied migration.
+func (c *Migrator) Down(ctx context.Context) ([]Migration, error) {
+	current, err := c.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := 0
+	for _, m := range c.Migrations {
+		if m.Version < current {
+			target = m.Version
+		}
+	}
+
+	return c.To(ctx, target)
+}
+
+// To applies or reverts migrations until the schema is at version
+// target, and returns the migrations it ran in order.
+func (c *Migrator) To(ctx context.Context, target int) ([]Migration, error) {
+	if target != 0 && c.find(target) == nil {
+		return nil, fmt.Errorf("unknown migration version %d", target)
+	}
+
+	unlock, err := c.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := c.DB.AutoMigrate(&schemaMigration{}).Error; err != nil {
+		return nil, err
+	}
+
+	applied, err := c.applied()
+	if err != nil {
+		return nil, err
+	}
+	for version := range applied {
+		if c.find(version) == nil {
+			return nil, fmt.Errorf("database has migration %d, which this build does not know", version)
+		}
+	}
+
+	ran := make([]Migration, 0)
+	for _, m := range c.Migrations {
+		if _, ok := applied[m.Version]; ok || m.Version > target {
+			continue
+		}
+
+		if err := c.run(m, m.Up, func(tx *gorm.DB) error {
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		}); err != nil {
+			return ran, fmt.Errorf("migration %d %s: %s", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+
+	for i := len(c.Migrations) - 1; i >= 0; i-- {
+		m := c.Migrations[i]
+		if _, ok := applied[m.Version]; !ok || m.Version <= target {
+			continue
+		}
+
+		if m.Down == nil {
+			return ran, fmt.Errorf("migration %d %s cannot be reverted", m.Version, m.Name)
+		}
+
+		if err := c.run(m, m.Down, func(tx *gorm.DB) error {
+			return tx.Delete(&schemaMigration{Version: m.Version}).Error
+		}); err != nil {
+			return ran, fmt.Errorf("reverting migration %d %s: %s", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+
+	return ran, nil
+}
+
+// run applies one step and records it in the same transaction.
+func (c *Migrator) run(m Migration, step, record func(tx *gorm.DB) error) error {
+	tx := c.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := step(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (c *Migrator) applied() (map[int]schemaMigration, error) {
+	applied := make(map[int]schemaMigration)
+	if !c.DB.HasTable(&schemaMigration{}) {
+		return applied, nil
+	}
+
+	rows := make([]schemaMigration, 0)
+	if err := c.DB.Order("version asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+func (c *Migrator) find(version int) *Migration {
+	for i := range c.Migrations {
+		if c.Migrations[i].Version == version {
+			return &c.Migrations[i]
+		}
+	}
+
+	return nil
+}
+
+// lock takes a Postgres session advisory lock so replicas starting
+// together do not migrate at the same time. The lock lives on its own
+// connection, since the pool would hand the unlock to any connection.
+// Other databases are not shared between processes and are not locked.
+func (c *Migrator) lock(ctx context.Context) (func(), error) {
+	if c.DB.Dialect().GetName() != "postgres" {
+		return func() {}, nil
+	}
+
+	conn, err := c.DB.DB().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+		conn.Close()
+	}, nil
+}
@@ -0,0 +1,64 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Migrations lists every schema change in version order. Append new
+// migrations at the end; never edit or reorder one that has shipped.
+var Migrations = []Migration{
+	{Version: 1, Name: "create todos", Up: createTodos, Down: Exec("DROP TABLE IF EXISTS todos")},
+	{
+		Version: 2,
+		Name:    "unique todo external ids",
+		Up: Exec(
+			// Older imports could race and give two todos the same
+			// external id. Keep it on the live todo created last and
+			// clear it on the others.
+			`UPDATE todos SET external_id = '' WHERE external_id <> '' AND id NOT IN (
+				SELECT DISTINCT ON (external_id) id FROM todos WHERE external_id <> ''
+				ORDER BY external_id, deleted_at IS NOT NULL, id DESC
+			)`,
+			"DROP INDEX IF EXISTS idx_todos_external_id",
+			"CREATE UNIQUE INDEX uix_todos_external_id ON todos (external_id) WHERE external_id <> ''",
+		),
+		Down: Exec(
+			"DROP INDEX IF EXISTS uix_todos_external_id",
+			"CREATE INDEX idx_todos_external_id ON todos (external_id)",
+		),
+	},
+}
+
+// todoV1 is the todos table as AutoMigrate created it before migrations
+// existed. createTodos only adds what is missing, so databases set up by
+// AutoMigrate adopt version 1 unchanged.
+type todoV1 struct {
+	gorm.Model
+	Title       string `gorm:"type:varchar(255)"`
+	Description string `gorm:"type:text"`
+	IsFavorite  bool
+	IsDone      bool
+	ExternalID  string `gorm:"type:varchar(255);index"`
+}
+
+func (todoV1) TableName() string {
+	return "todos"
+}
+
+func createTodos(tx *gorm.DB) error {
+	return tx.AutoMigrate(&todoV1{}).Error
+}
+
+// schemaMigration is a row of schema_migrations, one per applied
+// migration.
+type schemaMigration struct {
+	Version   int    `gorm:"primary_key;auto_increment:false"`
+	Name      string `gorm:"type:varchar(255)"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
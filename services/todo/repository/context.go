@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"log"
+
+	"github.com/ardiantirta/todo-crud/common/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+// discard swallows the notices gorm prints while registering callbacks.
+var discard = gorm.Logger{LogWriter: log.New(ioutil.Discard, "", 0)}
+
+// db returns the handle every query of the repository goes through, and
+// a func to call once the query and any rows or transaction it started
+// are done. Its statements run with ctx, so they are canceled with it and
+// traced as part of the request, over the pool and dialect of Conn.
+//
+// gorm v1 cannot swap the connection under a handle, and a handle from
+// gorm.Open starts from gorm's default callbacks, so registering
+// c.Callbacks again for every query would cost far more than the query's
+// own overhead; see BenchmarkDB. Handles are set up once and kept in a
+// pool instead, each over its own contextConn that is pointed at ctx for
+// the length of one call. If opening fails, the handle carries the error
+// and the query chained on it fails with it.
+func (c *TodoRepository) db(ctx context.Context) (*gorm.DB, func()) {
+	h, _ := c.handles.Get().(*handle)
+	if h == nil {
+		var err error
+		if h, err = c.open(); err != nil {
+			failed := c.Conn.New()
+			failed.AddError(err)
+			return failed, func() {}
+		}
+	}
+
+	h.conn.ctx = ctx
+	return tracing.WithContext(h.db, ctx), func() {
+		// A transaction points the shared dialect at itself.
+		h.db.Dialect().SetDB(h.conn)
+		h.conn.ctx = nil
+		c.handles.Put(h)
+	}
+}
+
+// handle is a gorm handle with the settings and callbacks of the
+// repository, over a contextConn that db points at the context of a call.
+type handle struct {
+	db   *gorm.DB
+	conn *contextConn
+}
+
+// open sets up a handle: logging off, since fail logs errors with the
+// request, and c.Callbacks. Callbacks registered on Conn itself are not
+// carried over.
+func (c *TodoRepository) open() (*handle, error) {
+	conn := &contextConn{db: c.Conn.DB()}
+	db, err := gorm.Open(c.Conn.Dialect().GetName(), conn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.LogMode(false)
+	db.SetLogger(discard)
+	if len(c.Callbacks) > 0 {
+		callback := db.Callback()
+		for _, register := range c.Callbacks {
+			register(callback)
+		}
+	}
+
+	return &handle{db: db, conn: conn}, nil
+}
+
+// contextConn runs the statements gorm sends with the context of the
+// call, so a client hanging up or a timeout cancels the query in the
+// database. gorm v1 takes no context itself. A transaction is bound to
+// the context too: it rolls back when the context is done.
+type contextConn struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+func (c *contextConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.db.ExecContext(c.ctx, query, args...)
+}
+
+func (c *contextConn) Prepare(query string) (*sql.Stmt, error) {
+	return c.db.PrepareContext(c.ctx, query)
+}
+
+func (c *contextConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.db.QueryContext(c.ctx, query, args...)
+}
+
+func (c *contextConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.db.QueryRowContext(c.ctx, query, args...)
+}
+
+func (c *contextConn) Begin() (*sql.Tx, error) {
+	return c.db.BeginTx(c.ctx, nil)
+}
+
+// BeginTx ignores ctx: gorm always passes context.Background.
+func (c *contextConn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.db.BeginTx(c.ctx, opts)
+}
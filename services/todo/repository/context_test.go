@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/ardiantirta/todo-crud/common/tracing"
+	"github.com/jinzhu/gorm"
+)
+
+// stubDriver hands out connections that can be opened but run nothing,
+// enough for gorm.Open to ping them.
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("stub: no statements") }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, errors.New("stub: no transactions") }
+
+func init() {
+	sql.Register("stub", stubDriver{})
+}
+
+func newStubRepository(t testing.TB) *TodoRepository {
+	sqlDB, err := sql.Open("stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := gorm.Open("postgres", sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &TodoRepository{Conn: conn, Callbacks: []func(*gorm.Callback){tracing.RegisterCallbacks}}
+}
+
+func TestDBGivesEveryCallItsOwnConnection(t *testing.T) {
+	repo := newStubRepository(t)
+	defer repo.Conn.Close()
+
+	type key struct{}
+	first, second := context.WithValue(context.Background(), key{}, 1), context.WithValue(context.Background(), key{}, 2)
+
+	db1, release1 := repo.db(first)
+	db2, release2 := repo.db(second)
+	conn1, conn2 := db1.CommonDB().(*contextConn), db2.CommonDB().(*contextConn)
+	if conn1 == conn2 || conn1.ctx != first || conn2.ctx != second {
+		t.Fatal("concurrent calls share a connection")
+	}
+	release1()
+	release2()
+
+	db3, release3 := repo.db(first)
+	defer release3()
+	if conn3 := db3.CommonDB().(*contextConn); conn3.ctx != first {
+		t.Error("a reused handle kept the context of its last call")
+	}
+}
+
+// BenchmarkDB compares taking a handle from the pool with setting one up
+// for every query.
+func BenchmarkDB(b *testing.B) {
+	repo := newStubRepository(b)
+	defer repo.Conn.Close()
+	ctx := context.Background()
+
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, release := repo.db(ctx)
+			release()
+		}
+	})
+	b.Run("open", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.open(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
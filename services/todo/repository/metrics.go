@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// InstrumentedRepository times every Repository call. For Each, Dump and
+// Restore the time includes the callback.
+type InstrumentedRepository struct {
+	Next     Repository
+	duration *prometheus.HistogramVec
+}
+
+func (c *InstrumentedRepository) observe(method string, start time.Time, err error) {
+	result := "ok"
+	switch {
+	case err == ErrNotFound:
+		result = "not_found"
+	case err == ErrTimeout:
+		result = "timeout"
+	case err == ErrCanceled:
+		result = "canceled"
+	case err != nil:
+		result = "error"
+	}
+
+	c.duration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+}
+
+func (c *InstrumentedRepository) Create(ctx context.Context, data *Todo) (*todo.CreateResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.Create(ctx, data)
+	c.observe("Create", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) Save(ctx context.Context, data *todo.ViewResponse) (*todo.ViewResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.Save(ctx, data)
+	c.observe("Save", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.GetByID(ctx, todoID)
+	c.observe("GetByID", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) GetByIDs(ctx context.Context, todoIDs []int) ([]todo.ViewResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.GetByIDs(ctx, todoIDs)
+	c.observe("GetByIDs", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.GetByTitle(ctx, params)
+	c.observe("GetByTitle", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.GetAll(ctx, params)
+	c.observe("GetAll", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) Each(ctx context.Context, params map[string]interface{}, fn func(*todo.ViewResponse) error) error {
+	start := time.Now()
+	err := c.Next.Each(ctx, params, fn)
+	c.observe("Each", start, err)
+	return err
+}
+
+func (c *InstrumentedRepository) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.GetByExternalIDs(ctx, externalIDs)
+	c.observe("GetByExternalIDs", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	start := time.Now()
+	resp, err := c.Next.SaveBatch(ctx, data)
+	c.observe("SaveBatch", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) GetChangedSince(ctx context.Context, since time.Time) ([]todo.ViewResponse, error) {
+	start := time.Now()
+	resp, err := c.Next.GetChangedSince(ctx, since)
+	c.observe("GetChangedSince", start, err)
+	return resp, err
+}
+
+func (c *InstrumentedRepository) DeleteByID(ctx context.Context, todoID int) error {
+	start := time.Now()
+	err := c.Next.DeleteByID(ctx, todoID)
+	c.observe("DeleteByID", start, err)
+	return err
+}
+
+func (c *InstrumentedRepository) Dump(ctx context.Context, fn func(*todo.ViewResponse) error) error {
+	start := time.Now()
+	err := c.Next.Dump(ctx, fn)
+	c.observe("Dump", start, err)
+	return err
+}
+
+func (c *InstrumentedRepository) Restore(ctx context.Context, next func() (*todo.ViewResponse, error)) error {
+	start := time.Now()
+	err := c.Next.Restore(ctx, next)
+	c.observe("Restore", start, err)
+	return err
+}
+
+func (c *InstrumentedRepository) Counts(ctx context.Context) (*Counts, error) {
+	start := time.Now()
+	resp, err := c.Next.Counts(ctx)
+	c.observe("Counts", start, err)
+	return resp, err
+}
+
+func NewInstrumentedRepository(next Repository, reg prometheus.Registerer) Repository {
+	c := &InstrumentedRepository{
+		Next: next,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "todo",
+			Subsystem: "repository",
+			Name:      "duration_seconds",
+			Help:      "Time taken by repository calls, by method and result.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "result"}),
+	}
+
+	reg.MustRegister(c.duration)
+
+	return c
+}
+
+// todoCollector reports how many todos are open, done and favorite. It
+// counts at scrape time, so the numbers are right whichever delivery or
+// replica changed them.
+type todoCollector struct {
+	repo     Repository
+	todos    *prometheus.Desc
+	favorite *prometheus.Desc
+}
+
+func NewTodoCollector(repo Repository) prometheus.Collector {
+	return &todoCollector{
+		repo:     repo,
+		todos:    prometheus.NewDesc("todo_todos", "Todos, soft-deleted ones left out, by state.", []string{"state"}, nil),
+		favorite: prometheus.NewDesc("todo_todos_favorite", "Todos marked as favorite, soft-deleted ones left out.", nil, nil),
+	}
+}
+
+func (c *todoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.todos
+	ch <- c.favorite
+}
+
+func (c *todoCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.repo.Counts(context.Background())
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.todos, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.todos, prometheus.GaugeValue, float64(counts.Open), "open")
+	ch <- prometheus.MustNewConstMetric(c.todos, prometheus.GaugeValue, float64(counts.Done), "done")
+	ch <- prometheus.MustNewConstMetric(c.favorite, prometheus.GaugeValue, float64(counts.Favorite))
+}
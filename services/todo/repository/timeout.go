@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// TimeoutRepository bounds every Repository call with the timeout of its
+// operation, or Default when it has none. Timeouts are keyed by the lower
+// case method name, as they come from the config. A timeout of 0 leaves
+// the call unbounded. Each, Dump and Restore stream through a callback
+// that may write to a slow client or read a large upload; their timeout
+// only runs while the database is being waited on, not during the
+// callback.
+type TimeoutRepository struct {
+	Next     Repository
+	Default  time.Duration
+	Timeouts map[string]time.Duration
+
+	mu sync.RWMutex
+}
+
+// SetTimeouts replaces Default and Timeouts while the repository is in
+// use. Calls already running keep the timeout they started with.
+func (c *TimeoutRepository) SetTimeouts(fallback time.Duration, timeouts map[string]time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Default = fallback
+	c.Timeouts = timeouts
+}
+
+func (c *TimeoutRepository) timeout(operation string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if timeout, ok := c.Timeouts[operation]; ok {
+		return timeout
+	}
+	return c.Default
+}
+
+func (c *TimeoutRepository) context(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	timeout := c.timeout(operation)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// streamContext is context for a streaming operation: its timeout is
+// paused while the callback runs.
+func (c *TimeoutRepository) streamContext(ctx context.Context, operation string) *pausableContext {
+	return newPausableContext(ctx, c.timeout(operation))
+}
+
+func (c *TimeoutRepository) Create(ctx context.Context, data *Todo) (*todo.CreateResponse, error) {
+	ctx, cancel := c.context(ctx, "create")
+	defer cancel()
+	return c.Next.Create(ctx, data)
+}
+
+func (c *TimeoutRepository) Save(ctx context.Context, data *todo.ViewResponse) (*todo.ViewResponse, error) {
+	ctx, cancel := c.context(ctx, "save")
+	defer cancel()
+	return c.Next.Save(ctx, data)
+}
+
+func (c *TimeoutRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewResponse, error) {
+	ctx, cancel := c.context(ctx, "getbyid")
+	defer cancel()
+	return c.Next.GetByID(ctx, todoID)
+}
+
+func (c *TimeoutRepository) GetByIDs(ctx context.Context, todoIDs []int) ([]todo.ViewResponse, error) {
+	ctx, cancel := c.context(ctx, "getbyids")
+	defer cancel()
+	return c.Next.GetByIDs(ctx, todoIDs)
+}
+
+func (c *TimeoutRepository) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	ctx, cancel := c.context(ctx, "getbytitle")
+	defer cancel()
+	return c.Next.GetByTitle(ctx, params)
+}
+
+func (c *TimeoutRepository) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	ctx, cancel := c.context(ctx, "getall")
+	defer cancel()
+	return c.Next.GetAll(ctx, params)
+}
+
+func (c *TimeoutRepository) Each(ctx context.Context, params map[string]interface{}, fn func(*todo.ViewResponse) error) error {
+	stream := c.streamContext(ctx, "each")
+	defer stream.stop()
+	return c.Next.Each(stream, params, func(data *todo.ViewResponse) error {
+		stream.pause()
+		defer stream.resume()
+		return fn(data)
+	})
+}
+
+func (c *TimeoutRepository) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	ctx, cancel := c.context(ctx, "getbyexternalids")
+	defer cancel()
+	return c.Next.GetByExternalIDs(ctx, externalIDs)
+}
+
+func (c *TimeoutRepository) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	ctx, cancel := c.context(ctx, "savebatch")
+	defer cancel()
+	return c.Next.SaveBatch(ctx, data)
+}
+
+func (c *TimeoutRepository) GetChangedSince(ctx context.Context, since time.Time) ([]todo.ViewResponse, error) {
+	ctx, cancel := c.context(ctx, "getchangedsince")
+	defer cancel()
+	return c.Next.GetChangedSince(ctx, since)
+}
+
+func (c *TimeoutRepository) DeleteByID(ctx context.Context, todoID int) error {
+	ctx, cancel := c.context(ctx, "deletebyid")
+	defer cancel()
+	return c.Next.DeleteByID(ctx, todoID)
+}
+
+func (c *TimeoutRepository) Dump(ctx context.Context, fn func(*todo.ViewResponse) error) error {
+	stream := c.streamContext(ctx, "dump")
+	defer stream.stop()
+	return c.Next.Dump(stream, func(data *todo.ViewResponse) error {
+		stream.pause()
+		defer stream.resume()
+		return fn(data)
+	})
+}
+
+func (c *TimeoutRepository) Restore(ctx context.Context, next func() (*todo.ViewResponse, error)) error {
+	stream := c.streamContext(ctx, "restore")
+	defer stream.stop()
+	return c.Next.Restore(stream, func() (*todo.ViewResponse, error) {
+		stream.pause()
+		defer stream.resume()
+		return next()
+	})
+}
+
+func (c *TimeoutRepository) Counts(ctx context.Context) (*Counts, error) {
+	ctx, cancel := c.context(ctx, "counts")
+	defer cancel()
+	return c.Next.Counts(ctx)
+}
+
+func NewTimeoutRepository(next Repository, fallback time.Duration, timeouts map[string]time.Duration) Repository {
+	return &TimeoutRepository{
+		Next:     next,
+		Default:  fallback,
+		Timeouts: timeouts,
+	}
+}
+
+// pausableContext is canceled once it has run for its timeout, not
+// counting the time it spent paused. Err reports context.DeadlineExceeded
+// then, like a context from context.WithTimeout, so fail maps it to
+// ErrTimeout. A timeout of 0 or less never expires.
+type pausableContext struct {
+	context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	left    time.Duration
+	started time.Time
+	timer   *time.Timer
+	expired bool
+}
+
+func newPausableContext(parent context.Context, timeout time.Duration) *pausableContext {
+	ctx, cancel := context.WithCancel(parent)
+
+	c := &pausableContext{Context: ctx, cancel: cancel, left: timeout}
+	c.resume()
+	return c
+}
+
+func (c *pausableContext) Err() error {
+	c.mu.Lock()
+	expired := c.expired
+	c.mu.Unlock()
+
+	if expired {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
+
+// pause stops the clock until resume.
+func (c *pausableContext) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer == nil {
+		return
+	}
+	if c.timer.Stop() {
+		c.left -= time.Since(c.started)
+	}
+	c.timer = nil
+}
+
+func (c *pausableContext) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil || c.expired || c.left <= 0 {
+		return
+	}
+	c.started = time.Now()
+	c.timer = time.AfterFunc(c.left, c.expire)
+}
+
+func (c *pausableContext) expire() {
+	c.mu.Lock()
+	// A parent that is already done keeps its own error.
+	c.expired = c.Context.Err() == nil
+	c.mu.Unlock()
+
+	c.cancel()
+}
+
+func (c *pausableContext) stop() {
+	c.pause()
+	c.cancel()
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// slowRepository takes delay to fetch every row of Each, as a slow query
+// would, and fails like TodoRepository once ctx is done.
+type slowRepository struct {
+	Repository
+	rows  int
+	delay time.Duration
+}
+
+func (c *slowRepository) Each(ctx context.Context, params map[string]interface{}, fn func(*todo.ViewResponse) error) error {
+	for i := 0; i < c.rows; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.delay):
+		}
+
+		if err := fn(&todo.ViewResponse{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestEachTimeoutSkipsTheCallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		callback time.Duration
+		err      error
+	}{
+		{"slow callback", 0, 40 * time.Millisecond, nil},
+		{"slow database", 40 * time.Millisecond, 0, context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		repo := NewTimeoutRepository(&slowRepository{rows: 5, delay: tt.delay}, time.Second, map[string]time.Duration{"each": 100 * time.Millisecond})
+		err := repo.Each(context.Background(), nil, func(*todo.ViewResponse) error {
+			time.Sleep(tt.callback)
+			return nil
+		})
+		if err != tt.err {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestPausableContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := newPausableContext(parent, 20*time.Millisecond)
+	defer ctx.stop()
+
+	ctx.pause()
+	time.Sleep(40 * time.Millisecond)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("paused context expired: %v", err)
+	}
+
+	cancel()
+	<-ctx.Done()
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("after the parent is canceled: err = %v, want %v", err, context.Canceled)
+	}
+	ctx.resume()
+	time.Sleep(40 * time.Millisecond)
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("a canceled context later reports %v", err)
+	}
+
+	ctx = newPausableContext(context.Background(), 0)
+	defer ctx.stop()
+	time.Sleep(10 * time.Millisecond)
+	if err := ctx.Err(); err != nil {
+		t.Errorf("a timeout of 0 expired: %v", err)
+	}
+}
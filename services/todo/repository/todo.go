@@ -3,8 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"io"
+	"github.com/ardiantirta/todo-crud/common/logging"
 	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
@@ -15,26 +19,68 @@ type Todo struct {
 	Description string `json:"description" gorm:"type:text"`
 	IsFavorite bool `json:"is_favorite"`
 	IsDone bool `json:"is_done"`
+	// ExternalID is unique among non-empty ids through the partial index
+	// uix_todos_external_id of migration 2, which SaveBatch's ON CONFLICT
+	// depends on. gorm tags cannot declare a partial index, so the schema
+	// lives in the migrations, not in this model.
+	ExternalID string `json:"external_id,omitempty" gorm:"type:varchar(255)"`
+}
+
+var (
+	ErrNotFound   = errors.New("todo not found")
+	ErrCreate     = errors.New("failed to create todo")
+	ErrSave       = errors.New("failed to save todo")
+	ErrGet        = errors.New("failed to get todo")
+	ErrGetChanged = errors.New("failed to get changed todo")
+	ErrDelete     = errors.New("failed to delete todo")
+	ErrDump       = errors.New("failed to dump todos")
+	ErrRestore    = errors.New("failed to restore todos")
+	ErrNotEmpty   = errors.New("todos table is not empty")
+	ErrCount      = errors.New("failed to count todos")
+	ErrTimeout    = errors.New("database operation timed out")
+	ErrCanceled   = errors.New("request canceled")
+)
+
+// Counts is how many todos there are, soft-deleted ones left out.
+type Counts struct {
+	Open     int
+	Done     int
+	Favorite int
 }
 
 type Repository interface {
 	Create(ctx context.Context, data *Todo) (*todo.CreateResponse, error)
 	Save(ctx context.Context, data *todo.ViewResponse) (*todo.ViewResponse, error)
 	GetByID(ctx context.Context, todoID int) (*todo.ViewResponse, error)
+	GetByIDs(ctx context.Context, todoIDs []int) ([]todo.ViewResponse, error)
 	GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error)
 	GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error)
+	Each(ctx context.Context, params map[string]interface{}, fn func(*todo.ViewResponse) error) error
+	GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error)
+	SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error)
+	GetChangedSince(ctx context.Context, since time.Time) ([]todo.ViewResponse, error)
 	DeleteByID(ctx context.Context, todoID int) error
+	Dump(ctx context.Context, fn func(*todo.ViewResponse) error) error
+	Restore(ctx context.Context, next func() (*todo.ViewResponse, error)) error
+	Counts(ctx context.Context) (*Counts, error)
 }
 
 type TodoRepository struct {
 	Conn *gorm.DB
+	// Callbacks are registered on the handle of every query; see db.
+	Callbacks []func(*gorm.Callback)
+
+	handles sync.Pool
 }
 
 func (c *TodoRepository) Create(ctx context.Context, data *Todo) (*todo.CreateResponse, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
 	response := new(todo.CreateResponse)
 
-	if err := c.Conn.Table("todos").Create(&data).Error; err != nil {
-		return nil, errors.New("failed to create todo")
+	if err := conn.Table("todos").Create(&data).Error; err != nil {
+		return nil, fail(ctx, err, ErrCreate)
 	}
 
 	response.ID = data.ID
@@ -42,6 +88,7 @@ func (c *TodoRepository) Create(ctx context.Context, data *Todo) (*todo.CreateRe
 	response.Description = data.Description
 	response.IsDone = data.IsDone
 	response.IsFavorite = data.IsFavorite
+	response.ExternalID = data.ExternalID
 	response.CreatedAt = data.CreatedAt
 	response.UpdatedAt = data.UpdatedAt
 	response.DeletedAt = data.DeletedAt
@@ -50,22 +97,31 @@ func (c *TodoRepository) Create(ctx context.Context, data *Todo) (*todo.CreateRe
 }
 
 func (c *TodoRepository) Save(ctx context.Context, data *todo.ViewResponse) (*todo.ViewResponse, error) {
-	if err := c.Conn.Table("todos").
+	conn, release := c.db(ctx)
+	defer release()
+
+	if err := conn.Table("todos").
 		Save(&data).Error; err != nil {
-			return nil, errors.New("failed to save todo")
+			return nil, fail(ctx, err, ErrSave)
 	}
 
 	return data, nil
 }
 
 func (c *TodoRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewResponse, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
 	response := new(todo.ViewResponse)
 
 	data := new(Todo)
-	if err := c.Conn.Table("todos").
+	if err := conn.Table("todos").
 		Where("id = ?", todoID).
 		First(&data).Error; err != nil {
-			return nil, errors.New("failed to get todo")
+			if gorm.IsRecordNotFoundError(err) {
+				return nil, ErrNotFound
+			}
+			return nil, fail(ctx, err, ErrGet)
 	}
 
 	response.ID = data.ID
@@ -73,6 +129,7 @@ func (c *TodoRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewRes
 	response.Description = data.Description
 	response.IsDone = data.IsDone
 	response.IsFavorite = data.IsFavorite
+	response.ExternalID = data.ExternalID
 	response.CreatedAt = data.CreatedAt
 	response.UpdatedAt = data.UpdatedAt
 	response.DeletedAt = data.DeletedAt
@@ -80,28 +137,92 @@ func (c *TodoRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewRes
 	return response, nil
 }
 
+func (c *TodoRepository) GetByIDs(ctx context.Context, todoIDs []int) ([]todo.ViewResponse, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
+	response := make([]todo.ViewResponse, 0)
+	if err := conn.Table("todos").
+		Where("id in (?)", todoIDs).
+		Find(&response).Error; err != nil {
+		return nil, fail(ctx, err, ErrGet)
+	}
+
+	return response, nil
+}
+
 func (c *TodoRepository) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
 	title := params["title"].(string)
 	title = "%" + title + "%"
 
 	response := make([]todo.ViewResponse, 0)
-	if err := c.Conn.Table("todos").
+	if err := conn.Table("todos").
 		Where("title like ?", title).
 		Find(&response).Error; err != nil {
-			return nil, errors.New("failed to get todo")
+			return nil, fail(ctx, err, ErrGet)
 	}
 
 	return response, nil
 }
 
 func (c *TodoRepository) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
 	response := make([]todo.ViewResponse, 0)
 
+	db := c.filter(conn.Table("todos"), params)
+	if limit, ok := params["limit"].(int); ok {
+		afterID, _ := params["after_id"].(int)
+		db = db.Where("id > ?", afterID).Order("id asc").Limit(limit)
+	}
+
+	if err := db.Find(&response).Error; err != nil {
+		return nil, fail(ctx, err, ErrGet)
+	}
+
+	return response, nil
+}
+
+// Each calls fn for every todo matching the GetAll filters, in id order,
+// without loading them all into memory.
+func (c *TodoRepository) Each(ctx context.Context, params map[string]interface{}, fn func(*todo.ViewResponse) error) error {
+	conn, release := c.db(ctx)
+	defer release()
+
+	db := conn.Model(&Todo{}).Table("todos")
+
+	rows, err := c.filter(db, params).Order("id asc").Rows()
+	if err != nil {
+		return fail(ctx, err, ErrGet)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		data := new(todo.ViewResponse)
+		if err := c.Conn.ScanRows(rows, data); err != nil {
+			return fail(ctx, err, ErrGet)
+		}
+
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fail(ctx, err, ErrGet)
+	}
+
+	return nil
+}
+
+func (c *TodoRepository) filter(db *gorm.DB, params map[string]interface{}) *gorm.DB {
 	isDoneStr := params["is_done"].(string)
 	isFavoriteStr := params["is_favorite"].(string)
 
-	db := c.Conn.Table("todos")
-
 	if len(isDoneStr) >= 4  {
 		isDoneBool, err := strconv.ParseBool(isDoneStr)
 		if err != nil {
@@ -120,25 +241,258 @@ func (c *TodoRepository) GetAll(ctx context.Context, params map[string]interface
 		}
 	}
 
-	if err := db.Find(&response).Error; err != nil {
-		return nil, errors.New("failed to get todo")
+	return db
+}
+
+func (c *TodoRepository) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
+	response := make([]todo.ViewResponse, 0)
+	if err := conn.Table("todos").
+		Where("external_id in (?)", externalIDs).
+		Find(&response).Error; err != nil {
+		return nil, fail(ctx, err, ErrGet)
+	}
+
+	return response, nil
+}
+
+// upsertTodo inserts a todo with an external id, or updates the todo that
+// already has it, soft-deleted or not. The unique index on external_id
+// makes this safe against concurrent imports of the same file; xmax is 0
+// for a row the statement inserted.
+const upsertTodo = `INSERT INTO todos (created_at, updated_at, title, description, is_favorite, is_done, external_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (external_id) WHERE external_id <> '' DO UPDATE SET
+	updated_at = excluded.updated_at,
+	deleted_at = NULL,
+	title = excluded.title,
+	description = excluded.description,
+	is_favorite = excluded.is_favorite,
+	is_done = excluded.is_done
+RETURNING id, created_at, xmax = 0`
+
+// SaveBatch creates or updates every todo in a single transaction and
+// reports for each whether it was created. Todos without an id are
+// created and get their id filled in, unless they have the external id of
+// a todo another request created since they were looked up, which is
+// then updated.
+func (c *TodoRepository) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
+	tx := conn.Begin()
+	if tx.Error != nil {
+		return nil, fail(ctx, tx.Error, ErrSave)
+	}
+
+	created := make([]bool, len(data))
+	for i, d := range data {
+		var err error
+		if d.ID == 0 && d.ExternalID != "" {
+			now := gorm.NowFunc()
+			if d.CreatedAt.IsZero() {
+				d.CreatedAt = now
+			}
+			d.UpdatedAt = now
+
+			err = tx.Raw(upsertTodo, d.CreatedAt, d.UpdatedAt, d.Title, d.Description, d.IsFavorite, d.IsDone, d.ExternalID).
+				Row().
+				Scan(&d.ID, &d.CreatedAt, &created[i])
+		} else {
+			created[i] = d.ID == 0
+			err = tx.Table("todos").Save(d).Error
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return nil, fail(ctx, err, ErrSave)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fail(ctx, err, ErrSave)
+	}
+
+	return created, nil
+}
+
+// GetChangedSince returns every todo created, updated or soft-deleted after
+// since, including the soft-deleted ones.
+func (c *TodoRepository) GetChangedSince(ctx context.Context, since time.Time) ([]todo.ViewResponse, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
+	response := make([]todo.ViewResponse, 0)
+	if err := conn.Unscoped().Table("todos").
+		Where("updated_at > ? OR deleted_at > ?", since, since).
+		Order("id asc").
+		Find(&response).Error; err != nil {
+		return nil, fail(ctx, err, ErrGetChanged)
 	}
 
 	return response, nil
 }
 
 func (c *TodoRepository) DeleteByID(ctx context.Context, todoID int) error {
-	if err := c.Conn.Table("todos").
+	conn, release := c.db(ctx)
+	defer release()
+
+	if err := conn.Table("todos").
 		Where("id = ?", todoID).
 		Delete(Todo{}).Error; err != nil {
-			return errors.New("failed to delete todo")
+			return fail(ctx, err, ErrDelete)
+	}
+
+	return nil
+}
+
+// Dump calls fn for every todo in id order, soft-deleted ones included.
+func (c *TodoRepository) Dump(ctx context.Context, fn func(*todo.ViewResponse) error) error {
+	conn, release := c.db(ctx)
+	defer release()
+
+	rows, err := conn.Unscoped().Model(&Todo{}).Table("todos").Order("id asc").Rows()
+	if err != nil {
+		return fail(ctx, err, ErrDump)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		data := new(todo.ViewResponse)
+		if err := c.Conn.ScanRows(rows, data); err != nil {
+			return fail(ctx, err, ErrDump)
+		}
+
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fail(ctx, err, ErrDump)
+	}
+
+	return nil
+}
+
+// Restore inserts the todos returned by next as they are, keeping their
+// ids and timestamps, until next returns io.EOF. It runs in a single
+// transaction into an empty table, so an error from next, such as a bad
+// checksum, leaves the database untouched.
+func (c *TodoRepository) Restore(ctx context.Context, next func() (*todo.ViewResponse, error)) error {
+	conn, release := c.db(ctx)
+	defer release()
+
+	tx := conn.Begin()
+	if tx.Error != nil {
+		return fail(ctx, tx.Error, ErrRestore)
+	}
+
+	var count int
+	if err := tx.Unscoped().Table("todos").Count(&count).Error; err != nil {
+		tx.Rollback()
+		return fail(ctx, err, ErrRestore)
+	}
+	if count > 0 {
+		tx.Rollback()
+		return ErrNotEmpty
+	}
+
+	for {
+		data, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Table("todos").Create(data).Error; err != nil {
+			tx.Rollback()
+			return fail(ctx, err, ErrRestore)
+		}
+	}
+
+	// Rows were inserted with explicit ids, so move the sequence past
+	// them for the todos created afterwards.
+	if tx.Dialect().GetName() == "postgres" {
+		if err := tx.Exec("SELECT setval(pg_get_serial_sequence('todos', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM todos").Error; err != nil {
+			tx.Rollback()
+			return fail(ctx, err, ErrRestore)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fail(ctx, err, ErrRestore)
 	}
 
 	return nil
 }
 
-func NewTodoRepository(Conn *gorm.DB) Repository {
+func (c *TodoRepository) Counts(ctx context.Context) (*Counts, error) {
+	conn, release := c.db(ctx)
+	defer release()
+
+	rows := make([]struct {
+		IsDone bool
+		Total  int
+	}, 0)
+	if err := conn.Model(&Todo{}).
+		Select("is_done, count(*) as total").
+		Group("is_done").
+		Scan(&rows).Error; err != nil {
+		return nil, fail(ctx, err, ErrCount)
+	}
+
+	response := new(Counts)
+	for _, row := range rows {
+		if row.IsDone {
+			response.Done = row.Total
+		} else {
+			response.Open = row.Total
+		}
+	}
+
+	if err := conn.Model(&Todo{}).
+		Where("is_favorite = ?", true).
+		Count(&response.Favorite).Error; err != nil {
+		return nil, fail(ctx, err, ErrCount)
+	}
+
+	return response, nil
+}
+
+// fail logs the database error behind err with the request it happened
+// in, since callers only get err. A query stopped because ctx is done
+// fails with ErrTimeout or ErrCanceled instead, so deliveries can tell
+// it from a database failure.
+func fail(ctx context.Context, cause, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		err = ErrTimeout
+	case context.Canceled:
+		err = ErrCanceled
+	}
+
+	entry := logging.FromContext(ctx).WithError(cause)
+	if err == ErrCanceled {
+		entry.Info(err.Error())
+		return err
+	}
+
+	entry.Error(err.Error())
+	return err
+}
+
+// NewTodoRepository queries through Conn. callbacks, such as
+// tracing.RegisterCallbacks, are registered on every handle the
+// repository opens instead of on gorm.DefaultCallback.
+func NewTodoRepository(Conn *gorm.DB, callbacks ...func(*gorm.Callback)) Repository {
 	return &TodoRepository{
-		Conn: Conn,
+		Conn:      Conn,
+		Callbacks: callbacks,
 	}
 }
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/common/tracing"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"go.opentelemetry.io/otel/api/kv"
+)
+
+// TracingRepository starts a span for every Repository call; the queries
+// it makes are traced as its children. For Each, Dump and Restore the span
+// includes the callback.
+type TracingRepository struct {
+	Next Repository
+}
+
+func (c *TracingRepository) Create(ctx context.Context, data *Todo) (*todo.CreateResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.Create")
+	resp, err := c.Next.Create(ctx, data)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) Save(ctx context.Context, data *todo.ViewResponse) (*todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.Save")
+	resp, err := c.Next.Save(ctx, data)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.GetByID", kv.Int("todo.id", todoID))
+	resp, err := c.Next.GetByID(ctx, todoID)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) GetByIDs(ctx context.Context, todoIDs []int) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.GetByIDs")
+	resp, err := c.Next.GetByIDs(ctx, todoIDs)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.GetByTitle")
+	resp, err := c.Next.GetByTitle(ctx, params)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.GetAll")
+	resp, err := c.Next.GetAll(ctx, params)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) Each(ctx context.Context, params map[string]interface{}, fn func(*todo.ViewResponse) error) error {
+	ctx, span := tracing.Start(ctx, "TodoRepository.Each")
+	err := c.Next.Each(ctx, params, fn)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func (c *TracingRepository) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.GetByExternalIDs")
+	resp, err := c.Next.GetByExternalIDs(ctx, externalIDs)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.SaveBatch")
+	resp, err := c.Next.SaveBatch(ctx, data)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) GetChangedSince(ctx context.Context, since time.Time) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.GetChangedSince")
+	resp, err := c.Next.GetChangedSince(ctx, since)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingRepository) DeleteByID(ctx context.Context, todoID int) error {
+	ctx, span := tracing.Start(ctx, "TodoRepository.DeleteByID", kv.Int("todo.id", todoID))
+	err := c.Next.DeleteByID(ctx, todoID)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func (c *TracingRepository) Dump(ctx context.Context, fn func(*todo.ViewResponse) error) error {
+	ctx, span := tracing.Start(ctx, "TodoRepository.Dump")
+	err := c.Next.Dump(ctx, fn)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func (c *TracingRepository) Restore(ctx context.Context, next func() (*todo.ViewResponse, error)) error {
+	ctx, span := tracing.Start(ctx, "TodoRepository.Restore")
+	err := c.Next.Restore(ctx, next)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func (c *TracingRepository) Counts(ctx context.Context) (*Counts, error) {
+	ctx, span := tracing.Start(ctx, "TodoRepository.Counts")
+	resp, err := c.Next.Counts(ctx)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func NewTracingRepository(next Repository) Repository {
+	return &TracingRepository{
+		Next: next,
+	}
+}
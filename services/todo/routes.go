@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/ardiantirta/todo-crud/common/health"
+	"github.com/ardiantirta/todo-crud/common/http/request"
+	"github.com/ardiantirta/todo-crud/common/http/response"
+	"github.com/ardiantirta/todo-crud/common/openapi"
+	"github.com/gorilla/mux"
+
+	todoCaldav "github.com/ardiantirta/todo-crud/services/todo/delivery/caldav"
+	todoGraphql "github.com/ardiantirta/todo-crud/services/todo/delivery/graphql"
+	todoHttp "github.com/ardiantirta/todo-crud/services/todo/delivery/http"
+	todoWeb "github.com/ardiantirta/todo-crud/services/todo/delivery/web"
+	todoWs "github.com/ardiantirta/todo-crud/services/todo/delivery/ws"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
+	"github.com/ardiantirta/todo-crud/services/todo/service"
+)
+
+// services are what the HTTP routes are served by.
+type services struct {
+	todo          service.Service
+	transfer      service.TransferService
+	markdown      service.MarkdownService
+	sync          service.SyncService
+	broker        *event.Broker
+	hub           *todoWs.Hub
+	health        *health.Health
+	calendarToken string
+	// checkOrigin decides which pages may open the websocket.
+	checkOrigin func(*http.Request) bool
+	// metrics is nil when metrics are served on their own port.
+	metrics http.Handler
+}
+
+// newRouter registers every HTTP route. Routes are matched in the order
+// they are registered, so /todo/export comes before /todo/{id}. Every
+// route should have an operation in doc, which main_test.go checks.
+func newRouter(s services, doc *openapi.Document) (*mux.Router, error) {
+	r := mux.NewRouter()
+
+	defaultHandler := request.NewDefaultHandler(response.NewDefaultJSONResponder())
+	r.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defaultHandler.Index(w, r)
+		return
+	})).Methods(http.MethodGet)
+
+	todoHttp.NewHealthHandler(r, s.health)
+	todoHttp.NewTransferHandler(r, s.transfer)
+	todoHttp.NewCalendarHandler(r, s.transfer, s.calendarToken)
+	todoHttp.NewMarkdownHandler(r, s.markdown)
+	todoHttp.NewTodoHandler(r, s.todo)
+	todoHttp.NewSyncHandler(r, s.sync)
+	todoWs.NewTodoHandler(r, s.todo, s.hub, s.checkOrigin)
+	todoWeb.NewTodoHandler(r, s.todo)
+	todoCaldav.NewTodoHandler(r, s.todo)
+	if err := todoGraphql.NewTodoHandler(r, s.todo, s.broker); err != nil {
+		return nil, err
+	}
+
+	todoHttp.NewOpenAPIHandler(r, doc)
+	if s.metrics != nil {
+		todoHttp.NewMetricsHandler(r, s.metrics, doc)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/config"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// newHTTPServer builds a server with the server.* timeouts and limits.
+// The write timeout is off by default: it bounds the whole response,
+// which would cut GraphQL subscriptions streamed as server-sent events.
+// Websockets set their own deadlines.
+func newHTTPServer(address string, cfg config.Server, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// bodyLimit is the request body limit for r. Imports upload whole files,
+// so they get server.max_import_bytes instead of server.max_body_bytes.
+// It runs before routing, hence the path rather than the route.
+func bodyLimit(cfg config.Server, r *http.Request) int64 {
+	if r.URL.Path == "/todo/import" {
+		return cfg.MaxImportBytes
+	}
+
+	return cfg.MaxBodyBytes
+}
+
+// serve runs srv until it fails or SIGTERM or SIGINT arrives. It returns
+// nil on a signal; the caller then shuts down.
+func serve(srv *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-signals:
+		logrus.WithField("signal", sig.String()).Info("shutting down")
+		return nil
+	}
+}
+
+// shutdownStep stops one component. It should give up when ctx is done.
+type shutdownStep struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// shutdown runs steps in order, all within a single timeout, and logs the
+// ones that fail or run out of time. Later steps still run, so the
+// database is closed even when requests did not drain in time.
+func shutdown(timeout time.Duration, steps ...shutdownStep) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, step := range steps {
+		start := time.Now()
+		err := step.stop(ctx)
+
+		entry := logrus.WithFields(logrus.Fields{
+			"component":  step.name,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		})
+		if err != nil {
+			entry.WithError(err).Error("shutdown")
+			continue
+		}
+		entry.Info("shutdown")
+	}
+}
+
+// stopHTTP lets in-flight requests finish, then closes the connections
+// still open when ctx is done.
+func stopHTTP(srv *http.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := srv.Shutdown(ctx); err != nil {
+			srv.Close()
+			return err
+		}
+
+		return nil
+	}
+}
+
+// stopGRPC lets in-flight calls and streams finish, then cancels the ones
+// still running when ctx is done.
+func stopGRPC(srv *grpc.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			srv.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ardiantirta/todo-crud/common/logging"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/sirupsen/logrus"
+)
+
+// LoggingService logs the errors Service returns with the logger of the
+// request they happened in, whichever delivery the request came through.
+// The database errors behind them are logged by the repository.
+type LoggingService struct {
+	Next Service
+}
+
+func (c *LoggingService) Create(ctx context.Context, form *todo.CreateRequest) (*todo.CreateResponse, error) {
+	resp, err := c.Next.Create(ctx, form)
+	logError(ctx, err, "Create", nil)
+	return resp, err
+}
+
+func (c *LoggingService) GetByID(ctx context.Context, id int) (*todo.ViewResponse, error) {
+	resp, err := c.Next.GetByID(ctx, id)
+	logError(ctx, err, "GetByID", logrus.Fields{"todo_id": id})
+	return resp, err
+}
+
+func (c *LoggingService) GetByIDs(ctx context.Context, ids []int) ([]todo.ViewResponse, error) {
+	resp, err := c.Next.GetByIDs(ctx, ids)
+	logError(ctx, err, "GetByIDs", nil)
+	return resp, err
+}
+
+func (c *LoggingService) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	resp, err := c.Next.GetByExternalIDs(ctx, externalIDs)
+	logError(ctx, err, "GetByExternalIDs", nil)
+	return resp, err
+}
+
+func (c *LoggingService) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	resp, err := c.Next.GetByTitle(ctx, params)
+	logError(ctx, err, "GetByTitle", nil)
+	return resp, err
+}
+
+func (c *LoggingService) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	resp, err := c.Next.GetAll(ctx, params)
+	logError(ctx, err, "GetAll", nil)
+	return resp, err
+}
+
+func (c *LoggingService) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	resp, err := c.Next.SaveBatch(ctx, data)
+	logError(ctx, err, "SaveBatch", logrus.Fields{"todos": len(data)})
+	return resp, err
+}
+
+func (c *LoggingService) UpdateData(ctx context.Context, id int, form *todo.UpdateRequest) (*todo.ViewResponse, error) {
+	resp, err := c.Next.UpdateData(ctx, id, form)
+	logError(ctx, err, "UpdateData", logrus.Fields{"todo_id": id})
+	return resp, err
+}
+
+func (c *LoggingService) MarkAsDone(ctx context.Context, id int, form *todo.DoneRequest) error {
+	err := c.Next.MarkAsDone(ctx, id, form)
+	logError(ctx, err, "MarkAsDone", logrus.Fields{"todo_id": id})
+	return err
+}
+
+func (c *LoggingService) MarkAsFavorite(ctx context.Context, id int, form *todo.FavoriteRequest) error {
+	err := c.Next.MarkAsFavorite(ctx, id, form)
+	logError(ctx, err, "MarkAsFavorite", logrus.Fields{"todo_id": id})
+	return err
+}
+
+func (c *LoggingService) DeleteByID(ctx context.Context, id int) error {
+	err := c.Next.DeleteByID(ctx, id)
+	logError(ctx, err, "DeleteByID", logrus.Fields{"todo_id": id})
+	return err
+}
+
+func logError(ctx context.Context, err error, operation string, fields logrus.Fields) {
+	if err == nil {
+		return
+	}
+
+	logging.FromContext(ctx).WithFields(fields).WithField("operation", operation).WithError(err).Warn("todo service error")
+}
+
+func NewLoggingService(next Service) Service {
+	return &LoggingService{
+		Next: next,
+	}
+}
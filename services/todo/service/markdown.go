@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ardiantirta/todo-crud/services/todo/codec"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+type MarkdownService interface {
+	Sync(ctx context.Context, form *todo.MarkdownSyncRequest) (*todo.MarkdownSyncResponse, error)
+}
+
+// TodoMarkdownService syncs a Markdown checklist with the todos. Items are
+// matched by their id comment: items without one are created, matched
+// items update the title, done state and description of their todo.
+// Writes go through TodoService so they are validated and published like
+// any other mutation.
+type TodoMarkdownService struct {
+	TodoService Service
+}
+
+func (c *TodoMarkdownService) Sync(ctx context.Context, form *todo.MarkdownSyncRequest) (*todo.MarkdownSyncResponse, error) {
+	rows, err := c.TodoService.GetAll(ctx, map[string]interface{}{
+		"is_done":     "",
+		"is_favorite": "",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make(map[uint]todo.ViewResponse, len(rows))
+	for _, row := range rows {
+		todos[row.ID] = row
+	}
+
+	response := &todo.MarkdownSyncResponse{
+		DryRun:  form.DryRun,
+		Created: make([]todo.MarkdownChange, 0),
+		Updated: make([]todo.MarkdownChange, 0),
+		Deleted: make([]todo.MarkdownChange, 0),
+		Errors:  make([]todo.ImportError, 0),
+	}
+
+	lines := strings.Split(form.Document, "\n")
+	seen := make(map[uint]bool)
+
+	for _, item := range codec.ParseChecklist(lines) {
+		change := todo.MarkdownChange{Line: item.Line + 1, ID: uint(item.ID), Title: item.Title}
+
+		if item.ID == 0 {
+			// A todo that was created but could not be marked as done
+			// still gets its comment, so the next sync does not create it
+			// again.
+			id, err := c.create(ctx, item, form.DryRun)
+			if id != 0 {
+				change.ID = id
+				seen[id] = true
+				lines[item.Line] = addComment(lines[item.Line], codec.ChecklistComment(id))
+			}
+			if err != nil {
+				response.Errors = append(response.Errors, todo.ImportError{Row: change.Line, Error: err.Error()})
+				continue
+			}
+
+			response.Created = append(response.Created, change)
+			continue
+		}
+
+		current, ok := todos[change.ID]
+		if !ok {
+			response.Errors = append(response.Errors, todo.ImportError{Row: change.Line, Error: fmt.Sprintf("todo %d does not exist", item.ID)})
+			continue
+		}
+		if seen[change.ID] {
+			response.Errors = append(response.Errors, todo.ImportError{Row: change.Line, Error: fmt.Sprintf("todo %d is listed more than once", item.ID)})
+			continue
+		}
+		seen[change.ID] = true
+
+		update := &todo.UpdateRequest{
+			Title:       item.Title,
+			Description: item.Description,
+			IsDone:      strconv.FormatBool(item.Done),
+			IsFavorite:  strconv.FormatBool(current.IsFavorite),
+		}
+		if update.Description == "" {
+			update.Description = current.Description
+		}
+
+		if update.Title == current.Title && update.Description == current.Description && item.Done == current.IsDone {
+			response.Unchanged++
+			continue
+		}
+
+		if err := update.Validate(); err != nil {
+			response.Errors = append(response.Errors, todo.ImportError{Row: change.Line, Error: err.Error()})
+			continue
+		}
+
+		if !form.DryRun {
+			if _, err := c.TodoService.UpdateData(ctx, item.ID, update); err != nil {
+				response.Errors = append(response.Errors, todo.ImportError{Row: change.Line, Error: err.Error()})
+				continue
+			}
+		}
+		response.Updated = append(response.Updated, change)
+	}
+
+	for _, row := range rows {
+		if !seen[row.ID] {
+			response.Deleted = append(response.Deleted, todo.MarkdownChange{ID: row.ID, Title: row.Title})
+		}
+	}
+
+	response.Document = strings.Join(lines, "\n")
+
+	return response, nil
+}
+
+// create adds the todo for a new item and returns its id, which is zero
+// on a dry run. The description falls back to the title.
+func (c *TodoMarkdownService) create(ctx context.Context, item codec.ChecklistItem, dryRun bool) (uint, error) {
+	form := &todo.CreateRequest{
+		Title:       item.Title,
+		Description: item.Description,
+	}
+	if form.Description == "" {
+		form.Description = item.Title
+	}
+
+	if err := form.Validate(); err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		return 0, nil
+	}
+
+	created, err := c.TodoService.Create(ctx, form)
+	if err != nil {
+		return 0, err
+	}
+
+	if item.Done {
+		update := &todo.UpdateRequest{
+			Title:       created.Title,
+			Description: created.Description,
+			IsDone:      "true",
+			IsFavorite:  "false",
+		}
+		if _, err := c.TodoService.UpdateData(ctx, int(created.ID), update); err != nil {
+			return created.ID, err
+		}
+	}
+
+	return created.ID, nil
+}
+
+// addComment appends comment to an item line, keeping a trailing "\r".
+func addComment(line, comment string) string {
+	if strings.HasSuffix(line, "\r") {
+		return strings.TrimRight(line, "\r ") + " " + comment + "\r"
+	}
+
+	return strings.TrimRight(line, " ") + " " + comment
+}
+
+func NewMarkdownService(todoService Service) MarkdownService {
+	return &TodoMarkdownService{
+		TodoService: todoService,
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+const (
+	// DefaultSyncOverlap is how long after its timestamp a change may
+	// commit and still reach clients that synced in between.
+	DefaultSyncOverlap = time.Minute
+
+	// MaxSyncSeen bounds the changes a sync token remembers.
+	MaxSyncSeen = 200
+)
+
+type SyncService interface {
+	Changes(ctx context.Context, token string) (*todo.ChangesResponse, error)
+	Sync(ctx context.Context, form *todo.SyncRequest) (*todo.SyncResponse, error)
+}
+
+// TodoSyncService implements delta sync for offline clients. Writes go
+// through TodoService so they are validated and published like any other
+// mutation.
+type TodoSyncService struct {
+	TodoRepository repository.Repository
+	TodoService    Service
+	ConflictPolicy string
+	Overlap        time.Duration
+}
+
+// Changes returns what changed after the token. Timestamps are taken when
+// a transaction writes but become visible when it commits, so a row can
+// show up with a time before the token. Changes therefore reads SyncOverlap
+// further back and skips the changes the token says were already sent.
+func (c *TodoSyncService) Changes(ctx context.Context, token string) (*todo.ChangesResponse, error) {
+	cursor, err := todo.DecodeSyncCursor(token)
+	if err != nil {
+		return nil, err
+	}
+
+	since := cursor.Since
+	if !since.IsZero() {
+		since = since.Add(-c.Overlap)
+	}
+	rows, err := c.TodoRepository.GetChangedSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &todo.ChangesResponse{
+		Todos:      make([]todo.ViewResponse, 0),
+		Tombstones: make([]todo.TombstoneView, 0),
+	}
+
+	// Times are compared as nanoseconds, as they come back from tokens.
+	type change struct {
+		id    uint
+		nanos int64
+	}
+	sent := make(map[change]time.Time, len(cursor.Seen))
+	for _, seen := range cursor.Seen {
+		sent[change{seen.ID, seen.ChangedAt.UnixNano()}] = seen.ChangedAt
+	}
+
+	next := todo.SyncCursor{Since: cursor.Since}
+	for _, row := range rows {
+		t := changedAt(row)
+		if t.After(next.Since) {
+			next.Since = t
+		}
+		key := change{row.ID, t.UnixNano()}
+		if _, ok := sent[key]; ok {
+			continue
+		}
+		sent[key] = t
+
+		if row.DeletedAt != nil {
+			response.Tombstones = append(response.Tombstones, todo.TombstoneView{
+				ID:        row.ID,
+				DeletedAt: *row.DeletedAt,
+			})
+			continue
+		}
+
+		response.Todos = append(response.Todos, row)
+	}
+
+	// Keep what the next call reads again. Past MaxSyncSeen the token
+	// would grow too long, so the client is sent those changes again;
+	// applying a todo twice does no harm.
+	for key, t := range sent {
+		if t.After(next.Since.Add(-c.Overlap)) {
+			next.Seen = append(next.Seen, todo.SyncSeen{ID: key.id, ChangedAt: t})
+		}
+	}
+	if len(next.Seen) > MaxSyncSeen {
+		next.Seen = nil
+	}
+	sort.Slice(next.Seen, func(i, j int) bool { return next.Seen[i].ID < next.Seen[j].ID })
+
+	response.Token = todo.EncodeSyncCursor(next)
+
+	return response, nil
+}
+
+// changedAt is when a row last changed, its deletion included.
+func changedAt(row todo.ViewResponse) time.Time {
+	t := row.UpdatedAt.UTC()
+	if row.DeletedAt != nil && row.DeletedAt.After(t) {
+		t = row.DeletedAt.UTC()
+	}
+
+	return t
+}
+
+func (c *TodoSyncService) Sync(ctx context.Context, form *todo.SyncRequest) (*todo.SyncResponse, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	base, _ := todo.DecodeSyncToken(form.Token)
+
+	response := &todo.SyncResponse{
+		Results: make([]todo.SyncResult, 0, len(form.Changes)),
+	}
+
+	for _, change := range form.Changes {
+		var result todo.SyncResult
+		if change.ID == 0 {
+			result = c.create(ctx, change)
+		} else {
+			result = c.apply(ctx, change, base)
+		}
+
+		result.ClientID = change.ClientID
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+func (c *TodoSyncService) create(ctx context.Context, change todo.SyncChange) todo.SyncResult {
+	if change.Deleted {
+		return todo.SyncResult{Status: todo.SyncApplied}
+	}
+
+	form := &todo.UpdateRequest{
+		IsDone:     "false",
+		IsFavorite: "false",
+	}
+	fill(form, change.Fields)
+	if err := form.Validate(); err != nil {
+		return rejected(err)
+	}
+
+	created, err := c.TodoService.Create(ctx, &todo.CreateRequest{
+		Title:       form.Title,
+		Description: form.Description,
+	})
+	if err != nil {
+		return rejected(err)
+	}
+
+	if form.IsDone == "false" && form.IsFavorite == "false" {
+		view := todo.ViewResponse(*created)
+		return todo.SyncResult{ID: created.ID, Status: todo.SyncApplied, Todo: &view}
+	}
+
+	resp, err := c.TodoService.UpdateData(ctx, int(created.ID), form)
+	if err != nil {
+		result := rejected(err)
+		result.ID = created.ID
+		return result
+	}
+
+	return todo.SyncResult{ID: resp.ID, Status: todo.SyncApplied, Todo: resp}
+}
+
+// apply merges a change into an existing todo field by field. A field is
+// only in conflict when the server changed it after the client's token:
+// its value differs from the base the client sent or, when the client
+// sent no base, the todo changed after the token at all. Other fields
+// take the client value; the conflict policy decides each conflicting
+// field on its own, and ServerFields reports the ones that kept the
+// server value.
+func (c *TodoSyncService) apply(ctx context.Context, change todo.SyncChange, base time.Time) todo.SyncResult {
+	server, err := c.TodoRepository.GetByID(ctx, change.ID)
+	if err != nil {
+		return rejected(err)
+	}
+
+	serverChanged := server.UpdatedAt.After(base)
+
+	if change.Deleted {
+		if serverChanged && !c.clientWins(change.ChangedAt, server.UpdatedAt) {
+			return todo.SyncResult{ID: server.ID, Status: todo.SyncRejected, Error: "todo changed on server", Todo: server}
+		}
+
+		if err := c.TodoService.DeleteByID(ctx, change.ID); err != nil {
+			return rejected(err)
+		}
+
+		return todo.SyncResult{ID: server.ID, Status: todo.SyncApplied}
+	}
+
+	current := &todo.UpdateRequest{
+		Title:       server.Title,
+		Description: server.Description,
+		IsDone:      strconv.FormatBool(server.IsDone),
+		IsFavorite:  strconv.FormatBool(server.IsFavorite),
+	}
+	form := *current
+
+	serverFields := make([]string, 0)
+	for _, field := range syncFields(&form, change) {
+		if field.value == nil || *field.value == *field.server {
+			continue
+		}
+
+		conflict := serverChanged && (field.base == nil || *field.base != *field.server)
+		if conflict && !c.clientWins(field.changedAt, server.UpdatedAt) {
+			serverFields = append(serverFields, field.name)
+			continue
+		}
+
+		*field.server = *field.value
+	}
+
+	if form == *current {
+		status := todo.SyncApplied
+		if len(serverFields) > 0 {
+			status = todo.SyncRejected
+		}
+
+		return todo.SyncResult{ID: server.ID, Status: status, ServerFields: serverFields, Todo: server}
+	}
+
+	resp, err := c.TodoService.UpdateData(ctx, change.ID, &form)
+	if err != nil {
+		return rejected(err)
+	}
+
+	status := todo.SyncApplied
+	if len(serverFields) > 0 {
+		status = todo.SyncMerged
+	}
+
+	return todo.SyncResult{ID: resp.ID, Status: status, ServerFields: serverFields, Todo: resp}
+}
+
+// clientWins reports whether a client change made at changedAt overrides
+// a conflicting server change made at serverAt.
+func (c *TodoSyncService) clientWins(changedAt, serverAt time.Time) bool {
+	return c.ConflictPolicy == todo.ConflictLastWriterWins && changedAt.After(serverAt)
+}
+
+// syncField is one field of a change. server points at the field of the
+// form being merged, which starts out with the server value.
+type syncField struct {
+	name      string
+	server    *string
+	value     *string
+	base      *string
+	changedAt time.Time
+}
+
+func syncFields(form *todo.UpdateRequest, change todo.SyncChange) []syncField {
+	at := func(changedAt *time.Time) time.Time {
+		if changedAt != nil {
+			return *changedAt
+		}
+		return change.ChangedAt
+	}
+
+	return []syncField{
+		{"title", &form.Title, change.Fields.Title, change.Base.Title, at(change.FieldsChangedAt.Title)},
+		{"description", &form.Description, change.Fields.Description, change.Base.Description, at(change.FieldsChangedAt.Description)},
+		{"is_done", &form.IsDone, change.Fields.IsDone, change.Base.IsDone, at(change.FieldsChangedAt.IsDone)},
+		{"is_favorite", &form.IsFavorite, change.Fields.IsFavorite, change.Base.IsFavorite, at(change.FieldsChangedAt.IsFavorite)},
+	}
+}
+
+func fill(form *todo.UpdateRequest, fields todo.SyncFields) {
+	if fields.Title != nil {
+		form.Title = *fields.Title
+	}
+	if fields.Description != nil {
+		form.Description = *fields.Description
+	}
+	if fields.IsDone != nil {
+		form.IsDone = *fields.IsDone
+	}
+	if fields.IsFavorite != nil {
+		form.IsFavorite = *fields.IsFavorite
+	}
+}
+
+func rejected(err error) todo.SyncResult {
+	return todo.SyncResult{Status: todo.SyncRejected, Error: err.Error()}
+}
+
+func NewSyncService(todoRepository repository.Repository, todoService Service, conflictPolicy string) SyncService {
+	if conflictPolicy != todo.ConflictServerWins {
+		conflictPolicy = todo.ConflictLastWriterWins
+	}
+
+	return &TodoSyncService{
+		TodoRepository: todoRepository,
+		TodoService:    todoService,
+		ConflictPolicy: conflictPolicy,
+		Overlap:        DefaultSyncOverlap,
+	}
+}
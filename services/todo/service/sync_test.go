@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/jinzhu/gorm"
+)
+
+// syncRepository holds a single todo.
+type syncRepository struct {
+	repository.Repository
+	todo todo.ViewResponse
+}
+
+func (c *syncRepository) GetByID(ctx context.Context, todoID int) (*todo.ViewResponse, error) {
+	data := c.todo
+	return &data, nil
+}
+
+// syncTodoService writes updates to the repository.
+type syncTodoService struct {
+	Service
+	repo *syncRepository
+}
+
+func (c *syncTodoService) UpdateData(ctx context.Context, id int, form *todo.UpdateRequest) (*todo.ViewResponse, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.repo.todo.Title = form.Title
+	c.repo.todo.Description = form.Description
+	c.repo.todo.IsDone, _ = strconv.ParseBool(form.IsDone)
+	c.repo.todo.IsFavorite, _ = strconv.ParseBool(form.IsFavorite)
+	return c.repo.GetByID(ctx, id)
+}
+
+func TestSyncMergesPerField(t *testing.T) {
+	synced := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	serverAt := synced.Add(time.Hour)
+	before, after := serverAt.Add(-time.Minute), serverAt.Add(time.Minute)
+
+	// The server changed the description and marked the todo done after
+	// the client synced at 10:00.
+	server := todo.ViewResponse{
+		Model:       gorm.Model{ID: 1, UpdatedAt: serverAt},
+		Title:       "buy milk",
+		Description: "two liters, skimmed",
+		IsDone:      true,
+	}
+	base := todo.SyncFields{
+		Title:       str("buy milk"),
+		Description: str("two liters of milk"),
+		IsDone:      str("false"),
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		change       todo.SyncChange
+		status       string
+		serverFields []string
+		want         todo.ViewResponse
+	}{
+		{
+			name:   "fields only the client changed are applied",
+			policy: todo.ConflictServerWins,
+			change: todo.SyncChange{
+				ChangedAt: before,
+				Fields:    todo.SyncFields{Title: str("buy oat milk"), IsFavorite: str("true")},
+				Base:      todo.SyncFields{Title: base.Title, IsFavorite: str("false")},
+			},
+			status: todo.SyncApplied,
+			want:   todo.ViewResponse{Title: "buy oat milk", Description: "two liters, skimmed", IsDone: true, IsFavorite: true},
+		},
+		{
+			name:   "server wins a conflict and the rest merges",
+			policy: todo.ConflictServerWins,
+			change: todo.SyncChange{
+				ChangedAt: after,
+				Fields:    todo.SyncFields{Title: str("buy oat milk"), Description: str("one liter of milk")},
+				Base:      base,
+			},
+			status:       todo.SyncMerged,
+			serverFields: []string{"description"},
+			want:         todo.ViewResponse{Title: "buy oat milk", Description: "two liters, skimmed", IsDone: true},
+		},
+		{
+			name:   "last writer wins is decided per field",
+			policy: todo.ConflictLastWriterWins,
+			change: todo.SyncChange{
+				ChangedAt:       before,
+				Fields:          todo.SyncFields{Description: str("one liter of milk"), IsDone: str("false")},
+				Base:            base,
+				FieldsChangedAt: todo.SyncTimes{IsDone: &after},
+			},
+			status:       todo.SyncMerged,
+			serverFields: []string{"description"},
+			want:         todo.ViewResponse{Title: "buy milk", Description: "two liters, skimmed"},
+		},
+		{
+			name:   "a change that loses every conflict is rejected",
+			policy: todo.ConflictServerWins,
+			change: todo.SyncChange{
+				ChangedAt: after,
+				Fields:    todo.SyncFields{IsDone: str("false")},
+				Base:      base,
+			},
+			status:       todo.SyncRejected,
+			serverFields: []string{"is_done"},
+			want:         todo.ViewResponse{Title: "buy milk", Description: "two liters, skimmed", IsDone: true},
+		},
+		{
+			name:   "without a base any field changed after the token conflicts",
+			policy: todo.ConflictServerWins,
+			change: todo.SyncChange{
+				ChangedAt: after,
+				Fields:    todo.SyncFields{Title: str("buy oat milk")},
+			},
+			status:       todo.SyncRejected,
+			serverFields: []string{"title"},
+			want:         todo.ViewResponse{Title: "buy milk", Description: "two liters, skimmed", IsDone: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &syncRepository{todo: server}
+			syncService := NewSyncService(repo, &syncTodoService{repo: repo}, test.policy)
+
+			change := test.change
+			change.ClientID, change.ID = "c1", 1
+			resp, err := syncService.Sync(context.Background(), &todo.SyncRequest{
+				Token:   todo.EncodeSyncToken(synced),
+				Changes: []todo.SyncChange{change},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := resp.Results[0]
+			if result.Status != test.status || result.Error != "" {
+				t.Errorf("status = %s %q, want %s", result.Status, result.Error, test.status)
+			}
+			if len(result.ServerFields) > 0 || len(test.serverFields) > 0 {
+				if !reflect.DeepEqual(result.ServerFields, test.serverFields) {
+					t.Errorf("server fields = %v, want %v", result.ServerFields, test.serverFields)
+				}
+			}
+
+			got := repo.todo
+			got.Model = gorm.Model{}
+			if got != test.want {
+				t.Errorf("todo = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func str(s string) *string {
+	return &s
+}
+
+// changesRepository returns the rows visible so far that changed after
+// since, like TodoRepository.GetChangedSince.
+type changesRepository struct {
+	repository.Repository
+	rows []todo.ViewResponse
+}
+
+func (c *changesRepository) GetChangedSince(ctx context.Context, since time.Time) ([]todo.ViewResponse, error) {
+	response := make([]todo.ViewResponse, 0)
+	for _, row := range c.rows {
+		if row.UpdatedAt.After(since) || row.DeletedAt != nil && row.DeletedAt.After(since) {
+			response = append(response, row)
+		}
+	}
+	return response, nil
+}
+
+func TestChangesReturnsLateCommits(t *testing.T) {
+	ten := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	deleted := ten.Add(-time.Second)
+	repo := &changesRepository{rows: []todo.ViewResponse{
+		{Model: gorm.Model{ID: 1, UpdatedAt: ten}, Title: "buy milk"},
+		{Model: gorm.Model{ID: 2, UpdatedAt: ten.Add(-time.Hour), DeletedAt: &deleted}, Title: "call mom"},
+	}}
+	syncService := NewSyncService(repo, nil, todo.ConflictServerWins)
+
+	ids := func(resp *todo.ChangesResponse) []uint {
+		var ids []uint
+		for _, data := range resp.Todos {
+			ids = append(ids, data.ID)
+		}
+		for _, tombstone := range resp.Tombstones {
+			ids = append(ids, tombstone.ID+100)
+		}
+		return ids
+	}
+	changes := func(token string, want ...uint) string {
+		t.Helper()
+		resp, err := syncService.Changes(context.Background(), token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(resp); !reflect.DeepEqual(got, want) {
+			t.Errorf("changes = %v, want %v", got, want)
+		}
+		return resp.Token
+	}
+
+	token := changes("", 1, 102)
+
+	// A transaction that wrote before 10:00 commits after the first call.
+	repo.rows = append(repo.rows, todo.ViewResponse{Model: gorm.Model{ID: 3, UpdatedAt: ten.Add(-30 * time.Second)}, Title: "water the plants"})
+	token = changes(token, 3)
+	token = changes(token)
+
+	repo.rows[0].UpdatedAt = ten.Add(5 * time.Minute)
+	token = changes(token, 1)
+	changes(token)
+
+	// A token from before cursors remembered what they sent still works;
+	// it gets the overlap again.
+	changes(todo.EncodeSyncToken(ten), 1, 3, 102)
+}
+
+func TestSyncCursorRoundTrip(t *testing.T) {
+	cursor := todo.SyncCursor{
+		Since: time.Date(2020, 1, 1, 10, 0, 0, 123456000, time.UTC),
+		Seen: []todo.SyncSeen{
+			{ID: 1, ChangedAt: time.Date(2020, 1, 1, 10, 0, 0, 123456000, time.UTC)},
+			{ID: 42, ChangedAt: time.Date(2020, 1, 1, 9, 59, 30, 0, time.UTC)},
+		},
+	}
+
+	got, err := todo.DecodeSyncCursor(todo.EncodeSyncCursor(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cursor) {
+		t.Errorf("cursor = %+v, want %+v", got, cursor)
+	}
+	if since, err := todo.DecodeSyncToken(todo.EncodeSyncCursor(cursor)); err != nil || !since.Equal(cursor.Since) {
+		t.Errorf("DecodeSyncToken = %s, %v", since, err)
+	}
+	for _, token := range []string{"not base64!", "bm90IGEgdGltZQ", "MjAyMC0wMS0wMVQxMDowMDowMFogMQ"} {
+		if _, err := todo.DecodeSyncCursor(token); err == nil {
+			t.Errorf("%q decoded", token)
+		}
+	}
+}
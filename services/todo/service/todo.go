@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"github.com/ardiantirta/todo-crud/services/todo/event"
 	"github.com/ardiantirta/todo-crud/services/todo/repository"
 	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
 	"strconv"
@@ -10,8 +11,11 @@ import (
 type Service interface {
 	Create(ctx context.Context, form *todo.CreateRequest) (*todo.CreateResponse, error)
 	GetByID(ctx context.Context, id int) (*todo.ViewResponse, error)
+	GetByIDs(ctx context.Context, ids []int) ([]todo.ViewResponse, error)
+	GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error)
 	GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error)
 	GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error)
+	SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error)
 	UpdateData(ctx context.Context, id int, form *todo.UpdateRequest) (*todo.ViewResponse, error)
 	MarkAsDone(ctx context.Context, id int, form *todo.DoneRequest) error
 	MarkAsFavorite(ctx context.Context,id int, form *todo.FavoriteRequest) error
@@ -20,6 +24,7 @@ type Service interface {
 
 type TodoService struct {
 	TodoRepository repository.Repository
+	Broker         *event.Broker
 }
 
 func (c *TodoService) Create(ctx context.Context, form *todo.CreateRequest) (*todo.CreateResponse, error) {
@@ -32,6 +37,7 @@ func (c *TodoService) Create(ctx context.Context, form *todo.CreateRequest) (*to
 		Description: form.Description,
 		IsFavorite:  false,
 		IsDone:      false,
+		ExternalID:  form.ExternalID,
 	}
 
 	response, err := c.TodoRepository.Create(ctx, data)
@@ -39,6 +45,8 @@ func (c *TodoService) Create(ctx context.Context, form *todo.CreateRequest) (*to
 		return nil, err
 	}
 
+	c.Broker.Publish(event.Event{Type: event.Created, Todo: todo.ViewResponse(*response)})
+
 	return response, nil
 }
 
@@ -51,6 +59,24 @@ func (c *TodoService) GetByID(ctx context.Context, id int) (*todo.ViewResponse,
 	return response, nil
 }
 
+func (c *TodoService) GetByIDs(ctx context.Context, ids []int) ([]todo.ViewResponse, error) {
+	response, err := c.TodoRepository.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *TodoService) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	response, err := c.TodoRepository.GetByExternalIDs(ctx, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
 func (c *TodoService) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
 	response, err := c.TodoRepository.GetByTitle(ctx, params)
 	if err != nil {
@@ -69,6 +95,33 @@ func (c *TodoService) GetAll(ctx context.Context, params map[string]interface{})
 	return response, nil
 }
 
+// SaveBatch creates or updates todos in a single transaction, as an import
+// does, and reports for each whether it was created. Todos are validated
+// with ImportRequest; one invalid todo fails the whole batch.
+func (c *TodoService) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	for _, d := range data {
+		form := &todo.ImportRequest{Title: d.Title, Description: d.Description}
+		if err := form.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := c.TodoRepository.SaveBatch(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, d := range data {
+		eventType := event.Updated
+		if created[i] {
+			eventType = event.Created
+		}
+		c.Broker.Publish(event.Event{Type: eventType, Todo: *d})
+	}
+
+	return created, nil
+}
+
 func (c *TodoService) UpdateData(ctx context.Context, id int, form *todo.UpdateRequest) (*todo.ViewResponse, error) {
 	if err := form.Validate(); err != nil {
 		return nil, err
@@ -92,6 +145,8 @@ func (c *TodoService) UpdateData(ctx context.Context, id int, form *todo.UpdateR
 		return nil, err
 	}
 
+	c.Broker.Publish(event.Event{Type: event.Updated, Todo: *response})
+
 	return response, nil
 }
 
@@ -109,11 +164,13 @@ func (c *TodoService) MarkAsDone(ctx context.Context, id int, form *todo.DoneReq
 
 	response.IsDone = isDone
 
-	_, err = c.TodoRepository.Save(ctx, response)
+	response, err = c.TodoRepository.Save(ctx, response)
 	if err != nil {
 		return err
 	}
 
+	c.Broker.Publish(event.Event{Type: event.Updated, Todo: *response})
+
 	return nil
 }
 
@@ -131,16 +188,18 @@ func (c *TodoService) MarkAsFavorite(ctx context.Context, id int, form *todo.Fav
 
 	response.IsFavorite = isFavorite
 
-	_, err = c.TodoRepository.Save(ctx, response)
+	response, err = c.TodoRepository.Save(ctx, response)
 	if err != nil {
 		return err
 	}
 
+	c.Broker.Publish(event.Event{Type: event.Updated, Todo: *response})
+
 	return nil
 }
 
 func (c *TodoService) DeleteByID(ctx context.Context, id int) error {
-	_, err := c.TodoRepository.GetByID(ctx, id)
+	response, err := c.TodoRepository.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -149,11 +208,14 @@ func (c *TodoService) DeleteByID(ctx context.Context, id int) error {
 		return err
 	}
 
+	c.Broker.Publish(event.Event{Type: event.Deleted, Todo: *response})
+
 	return nil
 }
 
-func NewTodoService(todoRepository repository.Repository) Service {
+func NewTodoService(todoRepository repository.Repository, broker *event.Broker) Service {
 	return &TodoService{
 		TodoRepository: todoRepository,
+		Broker:         broker,
 	}
 }
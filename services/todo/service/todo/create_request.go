@@ -8,6 +8,7 @@ import (
 type CreateRequest struct {
 	Title string `json:"title"`
 	Description string `json:"description"`
+	ExternalID string `json:"external_id,omitempty"`
 }
 
 func (c *CreateRequest) Validate() error {
@@ -0,0 +1,24 @@
+package todo
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ImportRequest is an imported row. Files from other tools hold short
+// tasks like "call mom", so only the title is required and the
+// description may be empty.
+type ImportRequest struct {
+	Title       string
+	Description string
+}
+
+func (c *ImportRequest) Validate() error {
+	validate := validator.New()
+	if err := validate.Var(c.Title, "required,max=100"); err != nil {
+		return errors.New("title harus diisi dan maksimal terdiri dari 100 karakter")
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package todo
+
+// ImportResponse summarises an import. With DryRun nothing was written
+// and Created and Updated count what would have been. Aborted is set when
+// the file could not be read to the end; rows before that point are kept.
+type ImportResponse struct {
+	DryRun  bool          `json:"dry_run"`
+	Total   int           `json:"total"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Failed  int           `json:"failed"`
+	Aborted bool          `json:"aborted"`
+	Errors  []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	Row        int    `json:"row"`
+	ExternalID string `json:"external_id,omitempty"`
+	Error      string `json:"error"`
+}
@@ -0,0 +1,31 @@
+package todo
+
+// MarkdownSyncRequest carries a Markdown checklist to sync. With DryRun
+// nothing is written and the response reports what would have changed.
+type MarkdownSyncRequest struct {
+	Document string `json:"document"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// MarkdownSyncResponse reports a sync. Document is the request document
+// with an id comment added to every item that was created, so the caller
+// can write it back. Deleted lists todos that no item refers to; they are
+// reported, never deleted.
+type MarkdownSyncResponse struct {
+	DryRun    bool             `json:"dry_run"`
+	Created   []MarkdownChange `json:"created"`
+	Updated   []MarkdownChange `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Deleted   []MarkdownChange `json:"deleted"`
+	Errors    []ImportError    `json:"errors"`
+	Document  string           `json:"document"`
+}
+
+// MarkdownChange is a todo the sync touched. Line is 1-based and zero for
+// todos that are not in the document; ID is zero for items a dry run
+// would create.
+type MarkdownChange struct {
+	Line  int    `json:"line,omitempty"`
+	ID    uint   `json:"id,omitempty"`
+	Title string `json:"title"`
+}
@@ -0,0 +1,162 @@
+package todo
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	ConflictLastWriterWins = "last-writer-wins"
+	ConflictServerWins     = "server-wins"
+)
+
+type SyncRequest struct {
+	Token   string       `json:"token"`
+	Changes []SyncChange `json:"changes"`
+}
+
+// SyncChange is a single client-side change. ID is zero for todos created
+// while offline; ClientID lets the client match the result to its record.
+//
+// Fields holds the new values and Base the values the client last synced
+// for them, which tells the fields the server changed since apart from
+// the ones only the client changed. FieldsChangedAt holds when each field
+// was changed, defaulting to ChangedAt.
+type SyncChange struct {
+	ClientID        string     `json:"client_id"`
+	ID              int        `json:"id"`
+	Deleted         bool       `json:"deleted"`
+	ChangedAt       time.Time  `json:"changed_at"`
+	Fields          SyncFields `json:"fields"`
+	Base            SyncFields `json:"base"`
+	FieldsChangedAt SyncTimes  `json:"fields_changed_at"`
+}
+
+type SyncFields struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	IsDone      *string `json:"is_done"`
+	IsFavorite  *string `json:"is_favorite"`
+}
+
+type SyncTimes struct {
+	Title       *time.Time `json:"title"`
+	Description *time.Time `json:"description"`
+	IsDone      *time.Time `json:"is_done"`
+	IsFavorite  *time.Time `json:"is_favorite"`
+}
+
+func (c *SyncRequest) Validate() error {
+	if _, err := DecodeSyncToken(c.Token); err != nil {
+		return err
+	}
+
+	validate := validator.New()
+	for _, change := range c.Changes {
+		if err := validate.Var(change.ClientID, "required"); err != nil {
+			return errors.New("client_id harus diisi")
+		}
+
+		if change.ID < 0 {
+			return errors.New("id harus berupa angka positif")
+		}
+
+		if change.ChangedAt.IsZero() {
+			return errors.New("changed_at harus diisi")
+		}
+	}
+
+	return nil
+}
+
+// SyncCursor is what a sync token holds: the time of the latest change a
+// client was sent, and the changes it was sent shortly before that. Those
+// are read again in case a transaction with an earlier timestamp commits
+// late, and Seen keeps them from being sent twice.
+type SyncCursor struct {
+	Since time.Time
+	Seen  []SyncSeen
+}
+
+// SyncSeen is a change a client was sent: a todo and the time it changed.
+type SyncSeen struct {
+	ID        uint
+	ChangedAt time.Time
+}
+
+// EncodeSyncCursor turns a cursor into the opaque token handed to
+// clients: the time, followed by id:unixnano pairs for the changes seen.
+func EncodeSyncCursor(cursor SyncCursor) string {
+	if cursor.Since.IsZero() {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(cursor.Since.UTC().Format(time.RFC3339Nano))
+	for i, seen := range cursor.Seen {
+		sep := ","
+		if i == 0 {
+			sep = " "
+		}
+		b.WriteString(sep + strconv.FormatUint(uint64(seen.ID), 10) + ":" + strconv.FormatInt(seen.ChangedAt.UnixNano(), 10))
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(b.String()))
+}
+
+// DecodeSyncCursor reads a token from EncodeSyncCursor. An empty token is
+// the beginning of time.
+func DecodeSyncCursor(token string) (SyncCursor, error) {
+	var cursor SyncCursor
+	if token == "" {
+		return cursor, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, errors.New("sync token tidak valid")
+	}
+
+	parts := strings.SplitN(string(b), " ", 2)
+	if cursor.Since, err = time.Parse(time.RFC3339Nano, parts[0]); err != nil {
+		return cursor, errors.New("sync token tidak valid")
+	}
+	if len(parts) == 1 {
+		return cursor, nil
+	}
+
+	for _, pair := range strings.Split(parts[1], ",") {
+		i := strings.Index(pair, ":")
+		if i < 0 {
+			return cursor, errors.New("sync token tidak valid")
+		}
+		id, err := strconv.ParseUint(pair[:i], 10, 64)
+		if err != nil {
+			return cursor, errors.New("sync token tidak valid")
+		}
+		nanos, err := strconv.ParseInt(pair[i+1:], 10, 64)
+		if err != nil {
+			return cursor, errors.New("sync token tidak valid")
+		}
+		cursor.Seen = append(cursor.Seen, SyncSeen{ID: uint(id), ChangedAt: time.Unix(0, nanos).UTC()})
+	}
+
+	return cursor, nil
+}
+
+// EncodeSyncToken turns a point in time into a token with nothing seen.
+func EncodeSyncToken(t time.Time) string {
+	return EncodeSyncCursor(SyncCursor{Since: t})
+}
+
+// DecodeSyncToken returns the time of a token. It treats an empty token
+// as the beginning of time.
+func DecodeSyncToken(token string) (time.Time, error) {
+	cursor, err := DecodeSyncCursor(token)
+	return cursor.Since, err
+}
@@ -0,0 +1,37 @@
+package todo
+
+import "time"
+
+const (
+	SyncApplied  = "applied"
+	SyncMerged   = "merged"
+	SyncRejected = "rejected"
+)
+
+type ChangesResponse struct {
+	Todos      []ViewResponse  `json:"todos"`
+	Tombstones []TombstoneView `json:"tombstones"`
+	Token      string          `json:"token"`
+}
+
+type TombstoneView struct {
+	ID        uint      `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type SyncResponse struct {
+	Results []SyncResult `json:"results"`
+}
+
+// SyncResult reports how a client change was resolved. A change is merged
+// when some of its fields were applied and others kept the server value;
+// ServerFields lists the fields the server changed since the client's
+// token and kept over the client value.
+type SyncResult struct {
+	ClientID     string        `json:"client_id"`
+	ID           uint          `json:"id"`
+	Status       string        `json:"status"`
+	ServerFields []string      `json:"server_fields,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Todo         *ViewResponse `json:"todo,omitempty"`
+}
@@ -8,4 +8,5 @@ type ViewResponse struct {
 	Description string `json:"description"`
 	IsDone bool `json:"is_done"`
 	IsFavorite bool `json:"is_favorite"`
+	ExternalID string `json:"external_id,omitempty"`
 }
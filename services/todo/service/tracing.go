@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ardiantirta/todo-crud/common/tracing"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"go.opentelemetry.io/otel/api/kv"
+)
+
+// TracingService starts a span for every Service call, whichever delivery
+// the request came through. The repository calls it makes are traced as
+// its children.
+type TracingService struct {
+	Next Service
+}
+
+func (c *TracingService) Create(ctx context.Context, form *todo.CreateRequest) (*todo.CreateResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.Create")
+	resp, err := c.Next.Create(ctx, form)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) GetByID(ctx context.Context, id int) (*todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetByID", kv.Int("todo.id", id))
+	resp, err := c.Next.GetByID(ctx, id)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) GetByIDs(ctx context.Context, ids []int) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetByIDs")
+	resp, err := c.Next.GetByIDs(ctx, ids)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetByExternalIDs")
+	resp, err := c.Next.GetByExternalIDs(ctx, externalIDs)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) GetByTitle(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetByTitle")
+	resp, err := c.Next.GetByTitle(ctx, params)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) GetAll(ctx context.Context, params map[string]interface{}) ([]todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.GetAll")
+	resp, err := c.Next.GetAll(ctx, params)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.SaveBatch", kv.Int("todo.count", len(data)))
+	resp, err := c.Next.SaveBatch(ctx, data)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) UpdateData(ctx context.Context, id int, form *todo.UpdateRequest) (*todo.ViewResponse, error) {
+	ctx, span := tracing.Start(ctx, "TodoService.UpdateData", kv.Int("todo.id", id))
+	resp, err := c.Next.UpdateData(ctx, id, form)
+	tracing.End(ctx, span, err)
+	return resp, err
+}
+
+func (c *TracingService) MarkAsDone(ctx context.Context, id int, form *todo.DoneRequest) error {
+	ctx, span := tracing.Start(ctx, "TodoService.MarkAsDone", kv.Int("todo.id", id))
+	err := c.Next.MarkAsDone(ctx, id, form)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func (c *TracingService) MarkAsFavorite(ctx context.Context, id int, form *todo.FavoriteRequest) error {
+	ctx, span := tracing.Start(ctx, "TodoService.MarkAsFavorite", kv.Int("todo.id", id))
+	err := c.Next.MarkAsFavorite(ctx, id, form)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func (c *TracingService) DeleteByID(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "TodoService.DeleteByID", kv.Int("todo.id", id))
+	err := c.Next.DeleteByID(ctx, id)
+	tracing.End(ctx, span, err)
+	return err
+}
+
+func NewTracingService(next Service) Service {
+	return &TracingService{
+		Next: next,
+	}
+}
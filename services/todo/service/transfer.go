@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/ardiantirta/todo-crud/services/todo/codec"
+	"github.com/ardiantirta/todo-crud/services/todo/repository"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+)
+
+// ImportBatchSize is the number of rows written per transaction.
+const ImportBatchSize = 500
+
+type TransferService interface {
+	Export(ctx context.Context, params map[string]interface{}, enc codec.Encoder) error
+	Import(ctx context.Context, dec codec.Decoder, dryRun bool) (*todo.ImportResponse, error)
+}
+
+// TodoTransferService moves todos in and out in bulk. Exports stream from
+// the repository; imports are written through TodoService, so they are
+// logged, traced and published like any other change. Imported rows are
+// validated with ImportRequest, which unlike Create accepts short titles
+// and empty descriptions; rows with an external id update the todo that
+// already has it instead of creating a new one.
+type TodoTransferService struct {
+	TodoRepository repository.Repository
+	TodoService    Service
+	BatchSize      int
+}
+
+func (c *TodoTransferService) Export(ctx context.Context, params map[string]interface{}, enc codec.Encoder) error {
+	if err := c.TodoRepository.Each(ctx, params, enc.Encode); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+func (c *TodoTransferService) Import(ctx context.Context, dec codec.Decoder, dryRun bool) (*todo.ImportResponse, error) {
+	response := &todo.ImportResponse{
+		DryRun: dryRun,
+		Errors: make([]todo.ImportError, 0),
+	}
+
+	batch := make([]*codec.Record, 0, c.BatchSize)
+	for {
+		record, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+
+		if rowErr, ok := err.(*codec.RowError); ok {
+			response.Total++
+			c.fail(response, rowErr.Row, "", rowErr.Err)
+			continue
+		}
+
+		if err != nil {
+			response.Aborted = true
+			c.fail(response, response.Total+1, "", err)
+			break
+		}
+
+		response.Total++
+		form := &todo.ImportRequest{Title: record.Title, Description: record.Description}
+		if err := form.Validate(); err != nil {
+			c.fail(response, record.Row, record.ExternalID, err)
+			continue
+		}
+
+		batch = append(batch, record)
+		if len(batch) == c.BatchSize {
+			c.importBatch(ctx, batch, dryRun, response)
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		c.importBatch(ctx, batch, dryRun, response)
+	}
+
+	return response, nil
+}
+
+// importBatch writes one batch in a transaction. When the transaction
+// fails every row of the batch is reported as failed.
+func (c *TodoTransferService) importBatch(ctx context.Context, batch []*codec.Record, dryRun bool, response *todo.ImportResponse) {
+	externalIDs := make([]string, 0, len(batch))
+	for _, record := range batch {
+		if record.ExternalID != "" {
+			externalIDs = append(externalIDs, record.ExternalID)
+		}
+	}
+
+	existing := make(map[string]*todo.ViewResponse)
+	if len(externalIDs) > 0 {
+		rows, err := c.TodoService.GetByExternalIDs(ctx, externalIDs)
+		if err != nil {
+			for _, record := range batch {
+				c.fail(response, record.Row, record.ExternalID, err)
+			}
+			return
+		}
+
+		for i := range rows {
+			existing[rows[i].ExternalID] = &rows[i]
+		}
+	}
+
+	// A later row with the same external id updates the todo of the
+	// earlier one, so the batch holds each todo once.
+	data := make([]*todo.ViewResponse, 0, len(batch))
+	queued := make(map[*todo.ViewResponse]bool)
+	created := make(map[*todo.ViewResponse]bool)
+	creates, updates := 0, 0
+	for _, record := range batch {
+		item, ok := existing[record.ExternalID]
+		if !ok || record.ExternalID == "" {
+			item = &todo.ViewResponse{ExternalID: record.ExternalID}
+			item.CreatedAt = record.CreatedAt
+			created[item] = true
+			creates++
+			if record.ExternalID != "" {
+				existing[record.ExternalID] = item
+			}
+		} else {
+			updates++
+		}
+
+		if !queued[item] {
+			queued[item] = true
+			data = append(data, item)
+		}
+
+		item.Title = record.Title
+		item.Description = record.Description
+		item.IsDone = record.IsDone
+		item.IsFavorite = record.IsFavorite
+	}
+
+	if !dryRun {
+		saved, err := c.TodoService.SaveBatch(ctx, data)
+		if err != nil {
+			for _, record := range batch {
+				c.fail(response, record.Row, record.ExternalID, err)
+			}
+			return
+		}
+
+		// Another import may have created a todo with the same external
+		// id since it was looked up; it was updated instead.
+		for i, item := range data {
+			if created[item] && !saved[i] {
+				creates--
+				updates++
+			}
+		}
+	}
+
+	response.Created += creates
+	response.Updated += updates
+}
+
+func (c *TodoTransferService) fail(response *todo.ImportResponse, row int, externalID string, err error) {
+	response.Failed++
+	response.Errors = append(response.Errors, todo.ImportError{
+		Row:        row,
+		ExternalID: externalID,
+		Error:      err.Error(),
+	})
+}
+
+func NewTransferService(todoRepository repository.Repository, todoService Service) TransferService {
+	return &TodoTransferService{
+		TodoRepository: todoRepository,
+		TodoService:    todoService,
+		BatchSize:      ImportBatchSize,
+	}
+}
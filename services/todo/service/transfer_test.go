@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ardiantirta/todo-crud/services/todo/codec"
+	"github.com/ardiantirta/todo-crud/services/todo/service/todo"
+	"github.com/jinzhu/gorm"
+)
+
+// importService has one todo, with external id "dentist", and saves
+// batches by recording them. Todos with an external id in taken were
+// created by someone else after the lookup, so saving updates them.
+type importService struct {
+	Service
+	taken   map[string]bool
+	batches [][]todo.ViewResponse
+}
+
+func (c *importService) GetByExternalIDs(ctx context.Context, externalIDs []string) ([]todo.ViewResponse, error) {
+	response := make([]todo.ViewResponse, 0)
+	for _, externalID := range externalIDs {
+		if externalID == "dentist" {
+			response = append(response, todo.ViewResponse{Model: gorm.Model{ID: 1}, Title: "dentist", ExternalID: "dentist"})
+		}
+	}
+	return response, nil
+}
+
+func (c *importService) SaveBatch(ctx context.Context, data []*todo.ViewResponse) ([]bool, error) {
+	batch := make([]todo.ViewResponse, 0, len(data))
+	created := make([]bool, len(data))
+	for i, d := range data {
+		batch = append(batch, *d)
+		created[i] = d.ID == 0 && !c.taken[d.ExternalID]
+	}
+	c.batches = append(c.batches, batch)
+	return created, nil
+}
+
+func TestImportWritesThroughTheService(t *testing.T) {
+	const file = `(A) call mom
+x dentist at 10:30 id:dentist
+water the plants id:plants
+read the paper id:paper
+`
+
+	tests := []struct {
+		name    string
+		dryRun  bool
+		taken   map[string]bool
+		created int
+		updated int
+		batches int
+	}{
+		{name: "import", created: 3, updated: 1, batches: 1},
+		{name: "dry run", dryRun: true, created: 3, updated: 1},
+		{name: "concurrent import", taken: map[string]bool{"plants": true}, created: 2, updated: 2, batches: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			todoService := &importService{taken: test.taken}
+			transfer := NewTransferService(nil, todoService)
+
+			dec, err := codec.NewDecoder(codec.FormatTodoTxt, strings.NewReader(file), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := transfer.Import(context.Background(), dec, test.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.Total != 4 || resp.Failed != 0 || resp.Created != test.created || resp.Updated != test.updated {
+				t.Errorf("response = %+v, want %d created and %d updated", resp, test.created, test.updated)
+			}
+			if len(todoService.batches) != test.batches {
+				t.Fatalf("saved %d batches, want %d", len(todoService.batches), test.batches)
+			}
+			if test.batches > 0 && todoService.batches[0][0].Title != "call mom" {
+				t.Errorf("first todo = %+v", todoService.batches[0][0])
+			}
+		})
+	}
+}
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	todoService := &importService{}
+	dec, err := codec.NewDecoder(codec.FormatNDJSON, strings.NewReader(`{"title": "buy milk"}`+"\n"+`{"title": ""}`+"\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := NewTransferService(nil, todoService).Import(context.Background(), dec, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.DryRun || resp.Created != 1 || resp.Failed != 1 || resp.Errors[0].Row != 2 {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(todoService.batches) != 0 {
+		t.Errorf("dry run saved %d batches", len(todoService.batches))
+	}
+}
+
+func TestImportUpdatesByExternalID(t *testing.T) {
+	const file = `[
+		{"title": "dentist at 11:00", "external_id": "dentist", "is_done": true},
+		{"title": "buy milk", "external_id": "milk"},
+		{"title": "buy oat milk", "external_id": "milk", "is_favorite": true},
+		{"title": "call mom"}
+	]`
+
+	todoService := &importService{}
+	dec, err := codec.NewDecoder(codec.FormatJSON, strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewTransferService(nil, todoService).Import(context.Background(), dec, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Created != 2 || resp.Updated != 2 {
+		t.Errorf("response = %+v, want 2 created and 2 updated", resp)
+	}
+
+	// The existing todo keeps its id, and the second "milk" row updates
+	// the todo the first one creates.
+	want := []todo.ViewResponse{
+		{Model: gorm.Model{ID: 1}, Title: "dentist at 11:00", ExternalID: "dentist", IsDone: true},
+		{Title: "buy oat milk", ExternalID: "milk", IsFavorite: true},
+		{Title: "call mom"},
+	}
+	if len(todoService.batches) != 1 || !reflect.DeepEqual(todoService.batches[0], want) {
+		t.Errorf("saved %+v, want %+v", todoService.batches, want)
+	}
+}